@@ -11,6 +11,7 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"golang.org/x/image/draw"
 )
 
 //go:embed *
@@ -21,10 +22,14 @@ var TitleFont = mustLoadFontFace("fonts/title.ttf")
 var ScoreFont = mustLoadFontFace("fonts/score.ttf")
 var LevelFont = mustLoadFontFace("fonts/score.ttf")
 var MeteorSprites = mustLoadImages("images/meteors/*.png")
+var MeteorSpritesMedium = mustLoadScaledImages("images/meteors/*.png", 0.65)
 var MeteorSpritesSmall = mustLoadImages("images/meteors-small/*.png")
+var MeteorSpritesShard = mustLoadScaledImages("images/meteors-small/*.png", 0.45)
 var LaserSprite = mustLoadImage("images/laser.png")
 var ExplosionSprite = mustLoadImage("images/explosion.png")
+var ExplosionMediumSprite = mustLoadScaledImage("images/explosion.png", 0.5)
 var ExplosionSmallSprite = mustLoadImage("images/explosion-small.png")
+var ExplosionShardSprite = mustLoadScaledImage("images/explosion-small.png", 0.5)
 var Explosion = createExplosion()
 var ThrustSound = mustLoadOggVorbis("audio/thrust.ogg")
 var ExhaustSprite = mustLoadImage("images/fire.png")
@@ -43,6 +48,34 @@ var AlienSprites = mustLoadImages("images/aliens/*.png")
 var AlienSound = mustLoadOggVorbis("audio/alien-sound.ogg")
 var AlienLaserSprite = mustLoadImage("images/red-laser.png")
 var AlienLaserSound = mustLoadOggVorbis("audio/alien-laser.ogg")
+var BossSprite = mustLoadScaledImage("images/aliens/alien2.png", 3)
+var BossSound = mustLoadOggVorbis("audio/alien.ogg")
+var TuningData = mustReadFile("data/tuning.json")
+var LevelData = mustReadFiles("data/levels/*.json")
+var FormationData = mustReadFile("data/formations.json")
+
+func mustReadFile(name string) []byte {
+	f, err := assets.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return f
+}
+
+func mustReadFiles(path string) [][]byte {
+	matches, err := fs.Glob(assets, path)
+	if err != nil {
+		panic(err)
+	}
+
+	files := make([][]byte, len(matches))
+	for i, match := range matches {
+		files[i] = mustReadFile(match)
+	}
+
+	return files
+}
 
 func mustLoadOggVorbis(name string) *vorbis.Stream {
 	f, err := assets.ReadFile(name)
@@ -80,6 +113,40 @@ func mustLoadImages(path string) []*ebiten.Image {
 	return images
 }
 
+func mustLoadScaledImages(path string, scale float64) []*ebiten.Image {
+	matches, err := fs.Glob(assets, path)
+	if err != nil {
+		panic(err)
+	}
+
+	images := make([]*ebiten.Image, len(matches))
+	for i, match := range matches {
+		images[i] = mustLoadScaledImage(match, scale)
+	}
+
+	return images
+}
+
+// mustLoadScaledImage loads an image and resizes it by scale, for sizes we don't have art for.
+func mustLoadScaledImage(name string, scale float64) *ebiten.Image {
+	f, err := assets.Open(name)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		panic(err)
+	}
+
+	b := img.Bounds()
+	scaled := image.NewRGBA(image.Rect(0, 0, int(float64(b.Dx())*scale), int(float64(b.Dy())*scale)))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, b, draw.Over, nil)
+
+	return ebiten.NewImageFromImage(scaled)
+}
+
 func mustLoadFontFace(name string) *text.GoTextFaceSource {
 	f, err := assets.ReadFile(name)
 	if err != nil {
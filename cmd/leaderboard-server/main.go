@@ -0,0 +1,40 @@
+// Command leaderboard-server hosts a shared high score table for Go Asteroids on the local
+// network. Scores are kept in a JSON file. Every submission carries its game's replay, which is
+// played back with the game's own logic before the score is accepted. That logic lives with the
+// rest of the game, so this links ebiten and builds wherever the game does.
+//
+//	go run ./cmd/leaderboard-server -addr :8080 -db scores.json
+//
+// Then start the game with -leaderboard http://<this machine>:8080.
+package main
+
+import (
+	"asteroids/leaderboard"
+	"flag"
+	"log"
+	"net/http"
+	"runtime"
+	"time"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "the address to listen on")
+	db := flag.String("db", "leaderboard.json", "the file scores are kept in")
+	workers := flag.Int("workers", runtime.NumCPU(), "how many replays to check at once")
+	timeout := flag.Duration("verify-timeout", 20*time.Second, "how long a replay gets to play back before it's refused")
+	flag.Parse()
+
+	store, err := leaderboard.OpenStore(*db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           leaderboard.NewHandler(store, newVerifier(*workers, *timeout).verify),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("Leaderboard listening on %s, keeping scores in %s", *addr, *db)
+	log.Fatal(server.ListenAndServe())
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"asteroids/goasteroids"
+	"asteroids/leaderboard"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	minTicksPerLevel   = 3 * goasteroids.TicksPerSecond       // Nobody clears a level quicker than this.
+	maxTicksPerLevel   = 20 * 60 * goasteroids.TicksPerSecond // Nobody spends longer than this on a level.
+	maxPointsPerSecond = 200                                  // Nobody scores quicker than this, boss bonus and all.
+	workerWait         = 10 * time.Second                     // How long a submission waits for a free worker before it's put off.
+)
+
+// verifier plays replays back on a fixed number of workers, so a burst of submissions can't tie
+// up the whole machine, and gives up on any replay that takes too long to play.
+type verifier struct {
+	workers chan struct{} // Holds a token for every replay being played.
+	wait    time.Duration // How long a submission waits for a free worker before it's put off.
+	timeout time.Duration // How long a replay gets to play out.
+}
+
+// newVerifier is a factory method which creates a verifier that plays up to workers replays at
+// once, giving each timeout.
+func newVerifier(workers int, timeout time.Duration) *verifier {
+	return &verifier{
+		workers: make(chan struct{}, max(workers, 1)),
+		wait:    workerWait,
+		timeout: timeout,
+	}
+}
+
+// verify plays a submission's replay back with the game's own logic, and checks it ends the way
+// the submission says it did. Anything that doesn't match is refused. Replays that can't be
+// played just yet, because every worker's busy, get leaderboard.ErrBusy.
+func (v *verifier) verify(ctx context.Context, s leaderboard.Submission) error {
+	r, err := checkReplay(s)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case v.workers <- struct{}{}:
+		defer func() { <-v.workers }()
+	case <-time.After(v.wait):
+		return leaderboard.ErrBusy
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+
+	err = playReplay(ctx, r, s)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("replay takes more than %s to check", v.timeout)
+	}
+	return err
+}
+
+// checkReplay decodes a submission's replay and makes the checks that don't need it played: it's
+// from this version of the game, with the default tuning and a built-in level set, with the
+// submitted seed, and about as long as a game that scores that much and gets that far.
+func checkReplay(s leaderboard.Submission) (*goasteroids.Replay, error) {
+	r := &goasteroids.Replay{}
+	if err := r.UnmarshalBinary(s.Replay); err != nil {
+		return nil, fmt.Errorf("bad replay: %w", err)
+	}
+
+	// Other versions may play out differently, so we can't check them.
+	if r.GameVersion != goasteroids.Version {
+		return nil, fmt.Errorf("replay is from version %s, the server checks version %s", r.GameVersion, goasteroids.Version)
+	}
+	if r.Tuning != goasteroids.DefaultTuning().Fingerprint() {
+		return nil, errors.New("replay was played with modified tuning")
+	}
+	if _, ok := goasteroids.BuiltInLevelSet(r.Levels); !ok {
+		return nil, fmt.Errorf("replay was played with custom levels %q", r.Levels)
+	}
+	if r.Seed != s.Seed {
+		return nil, fmt.Errorf("replay seed %d doesn't match submitted seed %d", r.Seed, s.Seed)
+	}
+	if s.Mode == string(goasteroids.ModeDaily) && !isDailySeed(s.Seed, s.Date) {
+		return nil, fmt.Errorf("seed %d isn't the daily challenge for %s", s.Seed, s.Date.Format("2006-01-02"))
+	}
+
+	// Playing a replay back is slow, so anything that can't be right is turned away first.
+	ticks := len(r.Frames)
+	switch {
+	case ticks < (s.Level-1)*minTicksPerLevel:
+		return nil, fmt.Errorf("%d frames is too short a game to reach level %d", ticks, s.Level)
+	case ticks > s.Level*maxTicksPerLevel:
+		return nil, fmt.Errorf("%d frames is too long a game to only reach level %d", ticks, s.Level)
+	case s.Score > (ticks/goasteroids.TicksPerSecond+1)*maxPointsPerSecond:
+		return nil, fmt.Errorf("%d frames is too short a game to score %d", ticks, s.Score)
+	}
+	return r, nil
+}
+
+// playReplay plays r back, and checks it ends with the score and level s claims.
+func playReplay(ctx context.Context, r *goasteroids.Replay, s leaderboard.Submission) error {
+	result, err := goasteroids.Simulate(ctx, r)
+	switch {
+	case err != nil:
+		return err
+	case !result.GameOver:
+		return errors.New("replay doesn't reach game over")
+	case result.Ticks != len(r.Frames):
+		return fmt.Errorf("game ends after %d of %d frames", result.Ticks, len(r.Frames))
+	case result.Score != s.Score:
+		return fmt.Errorf("replay scores %d, not %d", result.Score, s.Score)
+	case result.Level != s.Level:
+		return fmt.Errorf("replay reaches level %d, not %d", result.Level, s.Level)
+	}
+	return nil
+}
+
+// isDailySeed reports whether seed was the daily challenge around date. A game can finish the day
+// after it started, and the game's clock may be a little out, so a day either side counts.
+func isDailySeed(seed uint64, date time.Time) bool {
+	for _, d := range []time.Time{date.AddDate(0, 0, -1), date, date.AddDate(0, 0, 1)} {
+		if goasteroids.DailySeed(d) == seed {
+			return true
+		}
+	}
+	return false
+}
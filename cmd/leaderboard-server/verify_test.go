@@ -0,0 +1,196 @@
+package main
+
+import (
+	"asteroids/goasteroids"
+	"asteroids/leaderboard"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSeed = 1 // The seed the honest game is played with.
+
+// playHonestGame plays a game from testSeed, turning on the spot and firing until the ship's
+// lost, and returns the submission it makes.
+func playHonestGame(t *testing.T) leaderboard.Submission {
+	t.Helper()
+
+	levels, err := goasteroids.FindLevelSet(goasteroids.ClassicLevels)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := goasteroids.NewHeadlessGameScene(testSeed, goasteroids.DifficultyNormal, levels)
+	for tick := 0; g.Phase() != goasteroids.PhaseGameOver; tick++ {
+		if tick == 30*60*goasteroids.TicksPerSecond {
+			t.Fatal("the game was still going after half an hour")
+		}
+
+		frame := goasteroids.InputFrame(0).With(goasteroids.ActionRotateLeft)
+		if tick%2 == 0 {
+			frame = frame.With(goasteroids.ActionFire)
+		}
+		g.Step(frame)
+	}
+
+	replay, err := g.Replay().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaderboard.Submission{
+		ID:       leaderboard.NewID(),
+		Initials: "ABC",
+		Score:    g.Score(),
+		Level:    g.Level(),
+		Mode:     string(goasteroids.ModeSeeded),
+		Seed:     testSeed,
+		Date:     time.Now().UTC(),
+		Replay:   replay,
+	}
+}
+
+// submit posts s to a leaderboard that checks submissions with v, and returns the response.
+func submit(t *testing.T, v *verifier, s leaderboard.Submission) *httptest.ResponseRecorder {
+	t.Helper()
+
+	store, err := leaderboard.OpenStore(filepath.Join(t.TempDir(), "leaderboard.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	leaderboard.NewHandler(store, v.verify).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/scores", bytes.NewReader(body)))
+	return w
+}
+
+// withReplay returns s with its replay decoded, changed by change and encoded again.
+func withReplay(t *testing.T, s leaderboard.Submission, change func(*goasteroids.Replay)) leaderboard.Submission {
+	t.Helper()
+
+	r := &goasteroids.Replay{}
+	if err := r.UnmarshalBinary(s.Replay); err != nil {
+		t.Fatal(err)
+	}
+	change(r)
+
+	var err error
+	if s.Replay, err = r.MarshalBinary(); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestVerifyAcceptsHonestReplay(t *testing.T) {
+	s := playHonestGame(t)
+	if s.Score == 0 {
+		t.Fatal("the honest game didn't score anything, so it proves nothing")
+	}
+
+	if w := submit(t, newVerifier(1, time.Minute), s); w.Code != http.StatusCreated {
+		t.Errorf("status = %d (%s), want %d", w.Code, strings.TrimSpace(w.Body.String()), http.StatusCreated)
+	}
+}
+
+func TestVerifyRejectsTampering(t *testing.T) {
+	honest := playHonestGame(t)
+
+	tests := []struct {
+		name   string
+		tamper func(leaderboard.Submission) leaderboard.Submission
+		reason string
+	}{
+		{"score", func(s leaderboard.Submission) leaderboard.Submission {
+			s.Score++
+			return s
+		}, "replay scores"},
+		{"level", func(s leaderboard.Submission) leaderboard.Submission {
+			s.Level++
+			return s
+		}, "replay reaches level"},
+		{"submitted seed", func(s leaderboard.Submission) leaderboard.Submission {
+			s.Seed++
+			return s
+		}, "doesn't match submitted seed"},
+		{"replay seed", func(s leaderboard.Submission) leaderboard.Submission {
+			return withReplay(t, s, func(r *goasteroids.Replay) { r.Seed++ })
+		}, "doesn't match submitted seed"},
+		{"tuning fingerprint", func(s leaderboard.Submission) leaderboard.Submission {
+			return withReplay(t, s, func(r *goasteroids.Replay) { r.Tuning++ })
+		}, "modified tuning"},
+		{"custom levels", func(s leaderboard.Submission) leaderboard.Submission {
+			return withReplay(t, s, func(r *goasteroids.Replay) { r.Levels = "mylevels" })
+		}, "custom levels"},
+		{"frames cut short", func(s leaderboard.Submission) leaderboard.Submission {
+			return withReplay(t, s, func(r *goasteroids.Replay) { r.Frames = r.Frames[:len(r.Frames)/2] })
+		}, "doesn't reach game over"},
+		{"frames padded", func(s leaderboard.Submission) leaderboard.Submission {
+			return withReplay(t, s, func(r *goasteroids.Replay) { r.Frames = append(r.Frames, 0, 0, 0) })
+		}, "game ends after"},
+		{"score out of proportion", func(s leaderboard.Submission) leaderboard.Submission {
+			s.Score = 10_000_000
+			return s
+		}, "too short a game to score"},
+		{"level out of proportion", func(s leaderboard.Submission) leaderboard.Submission {
+			s.Level = 1000
+			return s
+		}, "too short a game to reach level"},
+		{"frames out of proportion", func(s leaderboard.Submission) leaderboard.Submission {
+			return withReplay(t, s, func(r *goasteroids.Replay) {
+				r.Frames = append(r.Frames, make([]goasteroids.InputFrame, maxTicksPerLevel)...)
+			})
+		}, "too long a game"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := submit(t, newVerifier(1, time.Minute), tt.tamper(honest))
+			if w.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusUnprocessableEntity)
+			}
+
+			var body leaderboard.ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(body.Error, tt.reason) {
+				t.Errorf("refused because %q, want %q", body.Error, tt.reason)
+			}
+		})
+	}
+}
+
+func TestVerifyGivesUpOnSlowReplays(t *testing.T) {
+	w := submit(t, newVerifier(1, time.Nanosecond), playHonestGame(t))
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "to check") {
+		t.Errorf("status = %d (%s), want a %d for taking too long", w.Code, strings.TrimSpace(w.Body.String()), http.StatusUnprocessableEntity)
+	}
+}
+
+func TestVerifyPutsOffSubmissionsWhenBusy(t *testing.T) {
+	v := newVerifier(1, time.Minute)
+	v.wait = time.Millisecond
+	v.workers <- struct{}{} // Someone else's replay is playing.
+
+	w := submit(t, v, playHonestGame(t))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+
+	// A submission that's given up on while it waits isn't played either.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	v.wait = time.Minute
+	if err := v.verify(ctx, playHonestGame(t)); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+}
@@ -5,7 +5,7 @@ go 1.24.0
 require (
 	github.com/hajimehoshi/ebiten/v2 v2.8.6
 	github.com/solarlune/resolv v0.8.1
-	golang.org/x/image v0.24.0 // indirect
+	golang.org/x/image v0.24.0
 )
 
 require (
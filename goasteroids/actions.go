@@ -0,0 +1,31 @@
+package goasteroids
+
+// Action is something the player can do in the game, regardless of which key or button triggers it.
+type Action int
+
+const (
+	ActionRotateLeft  Action = iota // Rotate the ship anticlockwise.
+	ActionRotateRight               // Rotate the ship clockwise.
+	ActionThrust                    // Fire the main engine.
+	ActionReverse                   // Back up.
+	ActionFire                      // Fire lasers.
+	ActionShield                    // Raise the shield.
+	ActionHyperspace                // Jump to a random spot on the screen.
+	ActionPause                     // Pause the game.
+	ActionConfirm                   // Choose the highlighted thing on a menu, or start a game.
+	ActionCycleWeapon               // Switch to the next weapon.
+)
+
+// InputFrame is the set of actions held down during one tick. It's everything the simulation
+// needs to know about input, so a list of frames is enough to play a whole game again.
+type InputFrame uint16
+
+// With returns a copy of the frame with action a held down.
+func (f InputFrame) With(a Action) InputFrame {
+	return f | 1<<a
+}
+
+// Has reports whether action a is held down in the frame.
+func (f InputFrame) Has(a Action) bool {
+	return f&(1<<a) != 0
+}
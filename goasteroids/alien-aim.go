@@ -0,0 +1,97 @@
+package goasteroids
+
+import (
+	"math"
+	"time"
+)
+
+// sighting is what the aliens saw of the ship on one tick.
+type sighting struct {
+	position Vector // Where the middle of the ship was.
+	velocity Vector // How far it was moving each tick.
+}
+
+// intercept returns where a shot from from, flying speed pixels per tick, meets a target at target
+// that's moving velocity pixels per tick: where to aim to lead it. It reports false, and returns
+// target, if the shot can never catch it.
+func intercept(from, target, velocity Vector, speed float64) (Vector, bool) {
+	// The shot meets the target after t ticks when |target + velocity*t - from| = speed*t, which
+	// squares up into a*t*t + b*t + c = 0.
+	d := target.Sub(from)
+	a := velocity.X*velocity.X + velocity.Y*velocity.Y - speed*speed
+	b := 2 * (d.X*velocity.X + d.Y*velocity.Y)
+	c := d.X*d.X + d.Y*d.Y
+
+	var t float64
+	if math.Abs(a) < 1e-9 {
+		// The target's as quick as the shot, so it can only be caught coming towards it.
+		if b >= 0 {
+			return target, false
+		}
+		t = -c / b
+	} else {
+		disc := b*b - 4*a*c
+		if disc < 0 {
+			return target, false
+		}
+		root := math.Sqrt(disc)
+		t1, t2 := (-b-root)/(2*a), (-b+root)/(2*a)
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		switch {
+		case t1 >= 0:
+			t = t1
+		case t2 >= 0:
+			t = t2
+		default:
+			return target, false
+		}
+	}
+
+	return target.Add(velocity.Scale(t)), true
+}
+
+// watchPlayer notes where the ship is and how it's moving, for the aliens to aim by. It keeps
+// enough ticks to go back a reaction time. It's called once per tick.
+func (g *GameScene) watchPlayer() {
+	g.sightings = append(g.sightings, sighting{position: g.player.centre(), velocity: g.player.velocity()})
+
+	_, reaction := g.alienAim()
+	if keep := ticks(reaction) + 1; len(g.sightings) > keep {
+		g.sightings = g.sightings[len(g.sightings)-keep:]
+	}
+}
+
+// alienAim returns how far off the aliens' aim can be, either side in radians, and how long they
+// take to react to what the ship does, on this level at the game's difficulty. Both shrink level by
+// level, from the tuning's starting values down to its deadliest.
+func (g *GameScene) alienAim() (spread float64, reaction time.Duration) {
+	t := g.tuning.Aliens
+
+	// How far they've come towards their deadliest: 0 on the first level, up to 1.
+	f := 1.0
+	if t.DeadliestLevel > 1 {
+		f = min(float64(g.currentLevel-1)/float64(t.DeadliestLevel-1), 1)
+	}
+	scale := g.difficulty.alienAimScale()
+
+	spread = (t.AimError + (t.DeadliestAimError-t.AimError)*f) * scale * math.Pi / 180
+	reaction = time.Duration((float64(t.ReactionTime) + float64(t.DeadliestReactionTime-t.ReactionTime)*f) * scale)
+	return spread, reaction
+}
+
+// aimAt returns the rotation for an alien at from to shoot at the ship. It leads the ship, going
+// by what it saw a reaction time ago, and is off by up to the aim error either way.
+func (g *GameScene) aimAt(from Vector) float64 {
+	spread, reaction := g.alienAim()
+	seen := g.sightings[max(len(g.sightings)-1-ticks(reaction), 0)]
+
+	target, _ := intercept(from, seen.position, seen.velocity, g.tuning.Aliens.LaserSpeed/TicksPerSecond)
+	return target.Sub(from).Rotation() + (g.random.aliens.Float64()*2-1)*spread
+}
+
+// ticks returns how many ticks d lasts.
+func ticks(d time.Duration) int {
+	return int(d.Milliseconds()) * TicksPerSecond / 1000
+}
@@ -0,0 +1,155 @@
+package goasteroids
+
+import (
+	"math"
+	"time"
+)
+
+const dodgerSwitchTime = 2 * time.Second // How often a dodger changes the way it's strafing.
+
+// AlienBehaviour is how an alien flies once it's come in. Its kind decides where it comes in from
+// (and how it shoots); its behaviour decides what it does after that.
+type AlienBehaviour string
+
+const (
+	BehaviourCruise   AlienBehaviour = "cruise"   // Carries straight on the way it came in, like aliens always have.
+	BehaviourSniper   AlienBehaviour = "sniper"   // Keeps its distance, circling the ship, and always shoots at it.
+	BehaviourKamikaze AlienBehaviour = "kamikaze" // Flies at the ship to ram it, and blows up when it does.
+	BehaviourDodger   AlienBehaviour = "dodger"   // Strafes about in front of the ship, and gets out of the way of lasers.
+)
+
+// alienBehaviours are the behaviours, in the order the level editor steps through them.
+var alienBehaviours = []AlienBehaviour{BehaviourCruise, BehaviourSniper, BehaviourKamikaze, BehaviourDodger}
+
+// steers reports whether aliens with the behaviour steer at all. Cruising ones don't.
+func (b AlienBehaviour) steers() bool {
+	return b != "" && b != BehaviourCruise
+}
+
+// The steering behaviours. Each returns the velocity an alien at pos would like to have to do one
+// thing; an alien's behaviour adds them up, weighted, and steers towards the total.
+
+// seek returns the velocity that takes pos straight to target at speed.
+func seek(pos, target Vector, speed float64) Vector {
+	return pos.Towards(target, speed)
+}
+
+// flee returns the velocity that takes pos straight away from threat at speed.
+func flee(pos, threat Vector, speed float64) Vector {
+	return threat.Towards(pos, speed)
+}
+
+// strafe returns the velocity that slides pos sideways across target's view at speed:
+// anticlockwise around it (as it looks on the screen) when side is 1, and clockwise when it's -1.
+func strafe(pos, target Vector, side, speed float64) Vector {
+	to := pos.Towards(target, speed)
+	return Vector{X: -to.Y * side, Y: to.X * side}
+}
+
+// orbit returns the velocity that circles pos around centre at radius, at speed, the way side
+// says (like strafe). It pulls in when it's too far out, and out when it's too close.
+func orbit(pos, centre Vector, radius, side, speed float64) Vector {
+	off := pos.Distance(centre) - radius
+	pull := max(-1, min(1, off/(radius/4)))
+	return strafe(pos, centre, side, speed).Add(seek(pos, centre, speed*pull)).Limit(speed)
+}
+
+// avoid returns the velocity that takes pos away from every threat closer than reach, more so the
+// closer it is. It's zero when there's nothing that close.
+func avoid(pos Vector, threats []Vector, reach, speed float64) Vector {
+	var away Vector
+	for _, t := range threats {
+		d := pos.Distance(t)
+		if d < reach {
+			away = away.Add(flee(pos, t, speed*(reach-d)/reach))
+		}
+	}
+	return away.Limit(speed)
+}
+
+// evade returns the velocity that takes pos out of the path of a shot at from, heading at
+// rotation: sideways to the path, on whichever side of it pos already is.
+func evade(pos, from Vector, rotation, speed float64) Vector {
+	path := heading(rotation, 1)
+	off := pos.Sub(from)
+	side := 1.0
+	if path.X*off.Y-path.Y*off.X < 0 {
+		side = -1
+	}
+	return Vector{X: -path.Y * side, Y: path.X * side}.Scale(speed)
+}
+
+// steer turns the alien's movement towards what its behaviour wants, no quicker than the aliens'
+// agility allows. Once it's stayed its time, it leaves, heading away from the ship.
+func (a *Alien) steer() {
+	if !a.behaviour.steers() {
+		return
+	}
+
+	t := a.game.tuning.Aliens
+	speed := t.SteeringSpeed / TicksPerSecond
+	ship := a.game.player.centre()
+
+	var want Vector
+	a.stayTimer.Update()
+	if a.stayTimer.IsReady() {
+		want = flee(a.position, ship, speed)
+	} else {
+		switch a.behaviour {
+		case BehaviourSniper:
+			want = orbit(a.position, ship, t.SniperRange, a.side, speed).
+				Add(a.avoidMeteors(speed).Scale(2))
+		case BehaviourKamikaze:
+			speed = t.KamikazeSpeed / TicksPerSecond
+			want = seek(a.position, ship, speed).
+				Add(a.avoidMeteors(speed).Scale(0.5))
+		case BehaviourDodger:
+			a.switchTimer.Update()
+			if a.switchTimer.IsReady() {
+				a.switchTimer.Reset()
+				a.side = -a.side
+			}
+			want = orbit(a.position, ship, t.DodgerRange, a.side, speed).
+				Add(a.avoidMeteors(speed)).
+				Add(a.evadeLasers(speed).Scale(3))
+		}
+	}
+
+	agility := t.Agility / TicksPerSecond / TicksPerSecond
+	a.movement = a.movement.Add(want.Limit(speed).Sub(a.movement).Limit(agility))
+}
+
+// avoidMeteors returns the velocity that keeps the alien clear of the meteors about it.
+func (a *Alien) avoidMeteors(speed float64) Vector {
+	var meteors []Vector
+	for _, k := range inOrder(a.game.meteors) {
+		if m := a.game.meteors[k]; !m.isExploding() {
+			meteors = append(meteors, m.centre())
+		}
+	}
+	return avoid(a.position, meteors, a.game.tuning.Aliens.AvoidRange, speed)
+}
+
+// evadeLasers returns the velocity that gets the alien out of the way of the player's lasers and
+// missiles heading for it.
+func (a *Alien) evadeLasers(speed float64) Vector {
+	t := a.game.tuning.Aliens
+	var away Vector
+	for _, k := range inOrder(a.game.projectiles) {
+		var rotation float64
+		switch s := a.game.projectiles[k].(type) {
+		case *Laser:
+			rotation = s.rotation
+		case *Missile:
+			rotation = s.rotation
+		default:
+			continue
+		}
+
+		from := a.game.projectiles[k].point()
+		if from.Distance(a.position) < t.EvadeRange && from.InCone(a.position, rotation, math.Pi/6) {
+			away = away.Add(evade(a.position, from, rotation, speed))
+		}
+	}
+	return away.Limit(speed)
+}
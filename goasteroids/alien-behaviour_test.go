@@ -0,0 +1,160 @@
+package goasteroids
+
+import (
+	"math"
+	"testing"
+)
+
+// sameVector reports whether a and b are the same, give or take rounding.
+func sameVector(a, b Vector) bool {
+	return closeTo(a.X, b.X) && closeTo(a.Y, b.Y)
+}
+
+func TestSteering(t *testing.T) {
+	origin := Vector{}
+
+	tests := []struct {
+		name string
+		got  Vector
+		want Vector
+	}{
+		{"seek", seek(origin, Vector{X: 3, Y: 4}, 10), Vector{X: 6, Y: 8}},
+		{"seek where it already is", seek(origin, origin, 10), Vector{}},
+		{"flee", flee(origin, Vector{X: 3, Y: 4}, 10), Vector{X: -6, Y: -8}},
+		{"strafe anticlockwise", strafe(origin, Vector{X: 10}, 1, 2), Vector{Y: 2}},
+		{"strafe clockwise", strafe(origin, Vector{X: 10}, -1, 2), Vector{Y: -2}},
+
+		// At the right distance an orbit is all strafe; a quarter of the radius out or more, it
+		// pulls in as hard as it goes round, and the total's held to the speed.
+		{"orbit on the circle", orbit(origin, Vector{X: 100}, 100, 1, 2), Vector{Y: 2}},
+		{"orbit too far out", orbit(origin, Vector{X: 200}, 100, 1, 2), Vector{X: math.Sqrt2, Y: math.Sqrt2}},
+		{"orbit too close", orbit(origin, Vector{X: 50}, 100, 1, 2), Vector{X: -math.Sqrt2, Y: math.Sqrt2}},
+		{"orbit a little close", orbit(origin, Vector{X: 90}, 100, 1, 2), Vector{X: -0.8, Y: 2}.Limit(2)},
+
+		{"avoid nothing", avoid(origin, nil, 50, 2), Vector{}},
+		{"avoid something out of reach", avoid(origin, []Vector{{X: 60}}, 50, 2), Vector{}},
+		{"avoid something halfway in", avoid(origin, []Vector{{X: 25}}, 50, 2), Vector{X: -1}},
+		{"avoid things all round", avoid(origin, []Vector{{X: 1}, {X: -1}}, 50, 2), Vector{}},
+		{"avoid several, held to the speed", avoid(origin, []Vector{{X: 1}, {Y: 1}}, 50, 2), Vector{X: -math.Sqrt2, Y: -math.Sqrt2}},
+
+		// A shot coming up from below: get out of its way on whichever side of it we are.
+		{"evade to the right", evade(Vector{X: 10}, Vector{Y: 100}, 0, 2), Vector{X: 2}},
+		{"evade to the left", evade(Vector{X: -10}, Vector{Y: 100}, 0, 2), Vector{X: -2}},
+		{"evade a shot going across", evade(Vector{Y: -5}, Vector{X: -100}, math.Pi/2, 2), Vector{Y: -2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !sameVector(tt.got, tt.want) {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSteerIsHeldToAgility(t *testing.T) {
+	g := emptyGame(t)
+	a := NewAlien(0, AlienHunter, BehaviourKamikaze, g)
+	a.movement = Vector{}
+
+	aliens := g.tuning.Aliens
+	agility := aliens.Agility / TicksPerSecond / TicksPerSecond
+	top := aliens.KamikazeSpeed / TicksPerSecond
+
+	// From a standstill it can only pick up agility's worth of speed a tick, straight at the ship.
+	a.steer()
+	if !closeTo(a.movement.Length(), agility) {
+		t.Errorf("speed after a tick = %v, want the agility's %v", a.movement.Length(), agility)
+	}
+	want := a.position.Towards(g.player.centre(), agility)
+	if !sameVector(a.movement, want) {
+		t.Errorf("movement = %v, want %v, straight at the ship", a.movement, want)
+	}
+
+	// However long it goes on, it never changes by more than that, or goes faster than it can.
+	for range 2 * TicksPerSecond {
+		before := a.movement
+		a.steer()
+		if change := a.movement.Sub(before).Length(); change > agility+1e-9 {
+			t.Fatalf("movement changed by %v in a tick, more than the agility's %v", change, agility)
+		}
+		if a.movement.Length() > top+1e-9 {
+			t.Fatalf("speed %v is more than a kamikaze's %v", a.movement.Length(), top)
+		}
+	}
+}
+
+func TestCruisersDontSteer(t *testing.T) {
+	g := emptyGame(t)
+	a := NewAlien(0, AlienHunter, BehaviourCruise, g)
+	before := a.movement
+
+	a.steer()
+	if a.movement != before {
+		t.Errorf("a cruising alien steered from %v to %v", before, a.movement)
+	}
+}
+
+// flyAlien puts an alien that flies the way behaviour says into an empty game, shields the ship
+// so nothing can end the run early, and steps the game for up to seconds. It calls watch with
+// the alien's distance from the ship every tick, and stops early if the alien's gone. It reports
+// how many ticks the alien lasted.
+func flyAlien(t *testing.T, behaviour AlienBehaviour, seconds int, watch func(tick int, distance float64)) int {
+	t.Helper()
+
+	g := emptyGame(t)
+	g.player.isShielded = true
+	a := NewAlien(0, AlienHunter, behaviour, g)
+	g.addAlien(a)
+
+	for tick := range seconds * TicksPerSecond {
+		g.Step(0)
+		if _, ok := g.alienOf(a.alienObj); !ok {
+			return tick
+		}
+		watch(tick, a.centre().Distance(g.player.centre()))
+	}
+	return seconds * TicksPerSecond
+}
+
+// holdsRange checks that, once it's had five seconds to get there, an alien flying the way
+// behaviour says keeps within a tenth of distance from the ship for the next three seconds.
+func holdsRange(t *testing.T, behaviour AlienBehaviour, distance float64) {
+	t.Helper()
+
+	settled := 5 * TicksPerSecond
+	lasted := flyAlien(t, behaviour, 8, func(tick int, d float64) {
+		if tick >= settled && math.Abs(d-distance) > distance/10 {
+			t.Fatalf("%s is %.0f from the ship after %d ticks, want about %.0f", behaviour, d, tick, distance)
+		}
+	})
+	if lasted < 8*TicksPerSecond {
+		t.Fatalf("%s was gone after %d ticks", behaviour, lasted)
+	}
+}
+
+func TestSniperHoldsItsRange(t *testing.T) {
+	holdsRange(t, BehaviourSniper, emptyGame(t).tuning.Aliens.SniperRange)
+}
+
+func TestDodgerHoldsItsRange(t *testing.T) {
+	holdsRange(t, BehaviourDodger, emptyGame(t).tuning.Aliens.DodgerRange)
+}
+
+func TestKamikazeClosesIn(t *testing.T) {
+	closest := math.Inf(1)
+	last := math.Inf(1)
+	lasted := flyAlien(t, BehaviourKamikaze, 8, func(tick int, d float64) {
+		if d > last {
+			t.Fatalf("kamikaze backed off from %.0f to %.0f after %d ticks", last, d, tick)
+		}
+		last, closest = d, min(closest, d)
+	})
+
+	// It goes up ramming the ship.
+	if lasted == 8*TicksPerSecond {
+		t.Fatalf("kamikaze was still about after 8 seconds, at best %.0f from the ship", closest)
+	}
+	if closest > 64 {
+		t.Errorf("kamikaze went before it got near the ship: at best %.0f away", closest)
+	}
+}
@@ -8,18 +8,15 @@ import (
 	"github.com/solarlune/resolv"
 )
 
-const (
-	alienLaserSpeedPerSecond = 1000.0
-)
-
 type AlienLaser struct {
 	position Vector
 	rotation float64
 	sprite   *ebiten.Image
 	laserObj *resolv.ConvexPolygon
+	speed    float64 // Pixels per second.
 }
 
-func NewAlienLaser(pos Vector, rotation float64) *AlienLaser {
+func NewAlienLaser(pos Vector, rotation, speed float64) *AlienLaser {
 	sprite := assets.AlienLaserSprite
 
 	bounds := sprite.Bounds()
@@ -32,17 +29,18 @@ func NewAlienLaser(pos Vector, rotation float64) *AlienLaser {
 	al := &AlienLaser{
 		position: pos,
 		rotation: rotation,
+		speed:    speed,
 		sprite:   sprite,
 		laserObj: resolv.NewRectangle(pos.X, pos.Y, float64(sprite.Bounds().Dx()), float64(sprite.Bounds().Dy())),
 	}
 	al.laserObj.SetPosition(pos.X, pos.Y)
-	al.laserObj.Tags().Set(TagLaser)
+	al.laserObj.Tags().Set(TagLaser | TagAlien) // An alien's laser, so it's told apart from the player's.
 
 	return al
 }
 
 func (al *AlienLaser) Update() {
-	speed := alienLaserSpeedPerSecond / float64(ebiten.TPS())
+	speed := al.speed / TicksPerSecond
 
 	al.position.X += math.Sin(al.rotation) * speed
 	al.position.Y += math.Cos(al.rotation) * -speed
@@ -3,7 +3,7 @@ package goasteroids
 import (
 	"asteroids/assets"
 	"math"
-	"math/rand/v2"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/solarlune/resolv"
@@ -18,23 +18,42 @@ type Alien struct {
 	angle         float64
 	movement      Vector
 	isIntelligent bool
+	behaviour     AlienBehaviour // How it flies once it's come in.
+	side          float64        // Which way round it strafes and circles the ship: 1 or -1.
+	stayTimer     *Timer         // How long a steering alien stays before it leaves.
+	switchTimer   *Timer         // When a dodger next changes the way it's strafing.
+	squadron      *Squadron      // The formation it flies in, if it does.
+	slot          int            // Its place in the formation.
+	flight        flight         // What it's up to in the formation.
+	diveTarget    Vector         // Where the ship was when it broke off to dive.
 }
 
-// NewAlien creates a new alien object.
-func NewAlien(baseVelocity float64, g *GameScene) *Alien {
+// NewAlien creates a new alien object of the given kind, which flies the way behaviour says once
+// it's come in.
+func NewAlien(baseVelocity float64, kind AlienKind, behaviour AlienBehaviour, g *GameScene) *Alien {
 	var alien Alien
 
-	// Get a random alien type (a number from 0-2).
-	alienType := rand.IntN(3)
+	var alienType int
+	switch kind {
+	case AlienFromRight:
+		alienType = 0
+	case AlienFromLeft:
+		alienType = 1
+	case AlienHunter:
+		alienType = 2
+	default:
+		// Get a random alien type (a number from 0-2).
+		alienType = g.random.aliens.IntN(3)
+	}
 
 	// Set a random sprite from those available to us.
-	sprite := assets.AlienSprites[rand.IntN(len(assets.AlienSprites))]
+	sprite := assets.AlienSprites[g.random.aliens.IntN(len(assets.AlienSprites))]
 
 	switch alienType {
 	case 0:
 		// Stupid alien that comes in from the right and shoots in random directions.
 		x := float64(ScreenWidth +100)
-		y := float64(rand.IntN(ScreenHeight-100) + 100)
+		y := float64(g.random.aliens.IntN(ScreenHeight-100) + 100)
 
 		target := Vector{X: 0, Y: y}
 
@@ -43,7 +62,7 @@ func NewAlien(baseVelocity float64, g *GameScene) *Alien {
 			Y: y,
 		}
 
-		velocity := baseVelocity + rand.Float64()*2.5
+		velocity := baseVelocity + g.random.aliens.Float64()*2.5
 
 		movement := Vector{
 			X: target.X - velocity,
@@ -64,7 +83,7 @@ func NewAlien(baseVelocity float64, g *GameScene) *Alien {
 	case 1:
 		// Stupid alien that comes in from the left and shoots in random directions.
 		x := -100.0
-		y := float64(rand.IntN(ScreenHeight-100) + 100)
+		y := float64(g.random.aliens.IntN(ScreenHeight-100) + 100)
 
 		target := Vector{X: 0, Y: y}
 
@@ -73,7 +92,7 @@ func NewAlien(baseVelocity float64, g *GameScene) *Alien {
 			Y: y,
 		}
 
-		velocity := baseVelocity + rand.Float64()*2.5
+		velocity := baseVelocity + g.random.aliens.Float64()*2.5
 
 		movement := Vector{
 			X: target.X + velocity,
@@ -100,7 +119,7 @@ func NewAlien(baseVelocity float64, g *GameScene) *Alien {
 		}
 
 		// Calculate the angle we are coming in from.
-		angle := rand.Float64() * 2 * math.Pi
+		angle := g.random.aliens.Float64() * 2 * math.Pi
 		r := ScreenWidth / 2.0
 
 		// Create the position.
@@ -110,7 +129,7 @@ func NewAlien(baseVelocity float64, g *GameScene) *Alien {
 		}
 
 		// Determine our velocity.
-		velocity := baseVelocity + rand.Float64()*1.5
+		velocity := baseVelocity + g.random.aliens.Float64()*1.5
 		target := g.player.position
 
 		direction := Vector{
@@ -137,11 +156,31 @@ func NewAlien(baseVelocity float64, g *GameScene) *Alien {
 		alien.alienObj.SetPosition(pos.X, pos.Y)
 	}
 
+	if behaviour.steers() {
+		alien.behaviour = behaviour
+		alien.stayTimer = NewTimer(time.Duration(g.tuning.Aliens.StayTime))
+		alien.switchTimer = NewTimer(dodgerSwitchTime)
+		// Aliens coming in nearer the top go round one way, and those nearer the bottom the other.
+		alien.side = 1
+		if alien.position.Y > ScreenHeight/2 {
+			alien.side = -1
+		}
+		// Snipers keep their distance so they can take aim.
+		if behaviour == BehaviourSniper {
+			alien.isIntelligent = true
+		}
+	}
+
 	alien.alienObj.Tags().Set(TagAlien)
 	return &alien
 }
 
 func (a *Alien) Update() {
+	a.steer()
+	if a.squadron != nil {
+		a.squadron.fly(a)
+	}
+
 	dx := a.movement.X
 	dy := a.movement.Y
 
@@ -151,6 +190,11 @@ func (a *Alien) Update() {
 	a.alienObj.SetPosition(a.position.X, a.position.Y)
 }
 
+// centre returns the middle of the alien. Aliens are drawn centred on their position.
+func (a *Alien) centre() Vector {
+	return a.position
+}
+
 func (a *Alien) Draw(screen *ebiten.Image) {
 	bounds := a.sprite.Bounds()
 	halfW := float64(bounds.Dx()) / 2
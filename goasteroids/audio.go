@@ -0,0 +1,134 @@
+package goasteroids
+
+import (
+	"asteroids/assets"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+)
+
+// Sound identifies one of the game's sound effects.
+type Sound int
+
+const (
+	SoundThrust Sound = iota
+	SoundLaserOne
+	SoundLaserTwo
+	SoundLaserThree
+	SoundExplosion
+	SoundBeatOne
+	SoundBeatTwo
+	SoundShieldsUp
+	SoundAlienLaser
+	SoundAlien
+	SoundBoss
+)
+
+// Audio is how the game asks for sounds. GameScene never touches an audio.Player itself, so a game
+// can run without a sound device by handing it Silence.
+type Audio interface {
+	// Play plays sound s from the start, unless it's already playing.
+	Play(s Sound)
+	// Restart rewinds sound s and plays it, even if it's already playing.
+	Restart(s Sound)
+	// Pause stops sound s if it's playing.
+	Pause(s Sound)
+}
+
+// Silence is an Audio that doesn't play anything.
+type Silence struct{}
+
+func (Silence) Play(Sound)    {}
+func (Silence) Restart(Sound) {}
+func (Silence) Pause(Sound)   {}
+
+// music are the sounds turned up and down by the music volume. Everything else is an effect.
+var music = map[Sound]bool{
+	SoundBeatOne: true,
+	SoundBeatTwo: true,
+	SoundAlien:   true,
+	SoundBoss:    true,
+}
+
+// loudness is how loud each sound is at full volume, if it isn't 1.
+var loudness = map[Sound]float64{
+	SoundAlien: 0.5,
+}
+
+// Speakers is an Audio that plays sounds through ebiten.
+type Speakers struct {
+	players map[Sound]*audio.Player
+}
+
+var speakers *Speakers // Created on first use; ebiten only allows one audio context.
+
+// defaultSpeakers returns the game's speakers, creating the audio context the first time it's
+// called.
+func defaultSpeakers() *Speakers {
+	if speakers != nil {
+		return speakers
+	}
+
+	audioContext := audio.NewContext(48000)
+
+	streams := map[Sound]*vorbis.Stream{
+		SoundThrust:     assets.ThrustSound,
+		SoundLaserOne:   assets.LaserOneSound,
+		SoundLaserTwo:   assets.LaserTwoSound,
+		SoundLaserThree: assets.LaserThreeSound,
+		SoundExplosion:  assets.ExplosionSound,
+		SoundBeatOne:    assets.BeatOneSound,
+		SoundBeatTwo:    assets.BeatTwoSound,
+		SoundShieldsUp:  assets.ShieldSound,
+		SoundAlienLaser: assets.AlienLaserSound,
+		SoundAlien:      assets.AlienSound,
+		SoundBoss:       assets.BossSound,
+	}
+
+	speakers = &Speakers{players: make(map[Sound]*audio.Player)}
+	for s, stream := range streams {
+		player, _ := audioContext.NewPlayer(stream)
+		speakers.players[s] = player
+	}
+	speakers.SetVolume(settings.MusicVolume, settings.SFXVolume)
+
+	return speakers
+}
+
+// SetVolume sets the volume of the music and of the sound effects, each from 0 to 1.
+func (sp *Speakers) SetVolume(musicVolume, sfxVolume float64) {
+	for s, player := range sp.players {
+		volume := sfxVolume
+		if music[s] {
+			volume = musicVolume
+		}
+		if l, ok := loudness[s]; ok {
+			volume *= l
+		}
+		player.SetVolume(volume)
+	}
+}
+
+// Play plays sound s from the start, unless it's already playing.
+func (sp *Speakers) Play(s Sound) {
+	player := sp.players[s]
+	if !player.IsPlaying() {
+		_ = player.Rewind()
+		player.Play()
+	}
+}
+
+// Restart rewinds sound s and plays it.
+func (sp *Speakers) Restart(s Sound) {
+	player := sp.players[s]
+	_ = player.Rewind()
+	player.Play()
+}
+
+// Pause stops sound s if it's playing.
+func (sp *Speakers) Pause(s Sound) {
+	player := sp.players[s]
+	if player.IsPlaying() {
+		player.Pause()
+	}
+}
@@ -0,0 +1,89 @@
+package goasteroids
+
+import (
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/solarlune/resolv"
+)
+
+const beamMinPower = 0.25 // The power of a beam fired without any charge.
+
+// Beam is what the beam cannon fires: a line of light that goes straight through everything in
+// its way, hitting each thing once.
+type Beam struct {
+	game    *GameScene            // The current game scene.
+	start   Vector                // Where it starts.
+	end     Vector                // Where it ends.
+	width   float64               // How wide it is.
+	timer   *Timer                // How long until it fades.
+	struck  map[uint32]bool       // The shapes it's already hit, by ID.
+	beamObj *resolv.ConvexPolygon // The collision object.
+}
+
+// NewBeam is a factory method which creates a beam from pos, heading at rotation. power is how
+// charged it was, from 0 to 1.
+func NewBeam(pos Vector, rotation float64, power float64, g *GameScene) *Beam {
+	t := g.tuning.Weapons
+	length := t.BeamLength * power
+	width := t.BeamWidth * power
+
+	// The beam's a long thin box along the direction the ship's pointing.
+	direction := Vector{X: math.Sin(rotation), Y: -math.Cos(rotation)}
+	across := Vector{X: -direction.Y * width / 2, Y: direction.X * width / 2}
+	end := Vector{X: pos.X + direction.X*length, Y: pos.Y + direction.Y*length}
+
+	beamObj := resolv.NewConvexPolygon(0, 0, []float64{
+		pos.X - across.X, pos.Y - across.Y,
+		end.X - across.X, end.Y - across.Y,
+		end.X + across.X, end.Y + across.Y,
+		pos.X + across.X, pos.Y + across.Y,
+	})
+	beamObj.Tags().Set(TagLaser)
+
+	return &Beam{
+		game:    g,
+		start:   pos,
+		end:     end,
+		width:   width,
+		timer:   NewTimer(time.Duration(t.BeamTime)),
+		struck:  make(map[uint32]bool),
+		beamObj: beamObj,
+	}
+}
+
+// Update fades the beam. It's called once per tick.
+func (b *Beam) Update() {
+	b.timer.Update()
+}
+
+// Draw draws the beam, fading as it goes.
+func (b *Beam) Draw(screen *ebiten.Image) {
+	alpha := uint8(255 * max(b.timer.Remaining(), 0.2))
+	vector.StrokeLine(screen, float32(b.start.X), float32(b.start.Y), float32(b.end.X), float32(b.end.Y), float32(b.width), color.RGBA{R: 120, G: 230, B: 255, A: alpha}, true)
+	vector.StrokeLine(screen, float32(b.start.X), float32(b.start.Y), float32(b.end.X), float32(b.end.Y), float32(b.width/3), color.RGBA{R: 255, G: 255, B: 255, A: alpha}, true)
+}
+
+func (b *Beam) shape() resolv.IShape {
+	return b.beamObj
+}
+
+func (b *Beam) point() Vector {
+	return b.start
+}
+
+// strike hits target, unless the beam's already hit it. The beam keeps going either way.
+func (b *Beam) strike(target resolv.IShape) bool {
+	if b.struck[target.ID()] || !b.beamObj.IsIntersecting(target) {
+		return false
+	}
+	b.struck[target.ID()] = true
+	return true
+}
+
+func (b *Beam) isSpent() bool {
+	return b.timer.IsReady()
+}
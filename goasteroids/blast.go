@@ -0,0 +1,53 @@
+package goasteroids
+
+import (
+	"asteroids/assets"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const blastTicks = 20 // How long a blast is shown for.
+
+// Blast is the ring of fire an explosive meteor leaves. It's only for show; the damage is done
+// the moment the meteor blows up.
+type Blast struct {
+	position Vector        // The middle of the blast.
+	radius   float64       // How far it reaches.
+	age      int           // Ticks since it went off.
+	sprite   *ebiten.Image // The image.
+}
+
+// NewBlast is a factory method which creates a new blast at pos, reaching radius.
+func NewBlast(pos Vector, radius float64) *Blast {
+	return &Blast{
+		position: pos,
+		radius:   radius,
+		sprite:   assets.ExplosionSprite,
+	}
+}
+
+// Update ages the blast. It's called once per tick.
+func (b *Blast) Update() {
+	b.age++
+}
+
+// isOver reports whether the blast has finished, and can be removed.
+func (b *Blast) isOver() bool {
+	return b.age >= blastTicks
+}
+
+// Draw draws the blast, growing out to its radius and fading as it goes.
+func (b *Blast) Draw(screen *ebiten.Image) {
+	progress := float64(b.age) / blastTicks
+	bounds := b.sprite.Bounds()
+	scale := 2 * b.radius * (0.4 + 0.6*progress) / float64(bounds.Dx())
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-float64(bounds.Dx())/2, -float64(bounds.Dy())/2)
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(b.position.X, b.position.Y)
+	op.ColorScale.Scale(1.6, 0.8, 0.3, 1)
+	op.ColorScale.ScaleAlpha(float32(1 - progress))
+
+	screen.DrawImage(b.sprite, op)
+}
@@ -0,0 +1,345 @@
+package goasteroids
+
+import (
+	"asteroids/assets"
+	"image/color"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/solarlune/resolv"
+)
+
+const (
+	bossTop          = 170.0                   // How far down the screen the middle of the boss stops.
+	bossMargin       = 260.0                   // How close to the sides of the screen it sweeps.
+	bossCoreRadius   = 36.0                    // How big the core is.
+	bossTurretRadius = 18.0                    // How big each turret is.
+	bossHitFlash     = 6                       // How many ticks a part flashes for when it's hit.
+	bossDyingTime    = 1500 * time.Millisecond // How long it takes to blow up once the core's gone.
+)
+
+// bossHull is the outline of the boss's armour, around its middle, as x, y pairs.
+var bossHull = []float64{-156, -100, 156, -100, 150, 0, 40, 120, -40, 120, -150, 0}
+
+// bossCoreSpot is where the core sits, from the middle of the boss.
+var bossCoreSpot = Vector{X: 0, Y: -10}
+
+// bossTurretSpots are where the turrets sit, from the middle of the boss.
+var bossTurretSpots = []Vector{{X: -120, Y: -60}, {X: 120, Y: -60}, {X: -70, Y: 50}, {X: 70, Y: 50}}
+
+// BossPhase is how the boss attacks. It gets nastier the more it's hurt.
+type BossPhase int
+
+const (
+	BossPhaseLaserFan BossPhase = iota // Its turrets fire fans of lasers. The core's armoured until they're all gone.
+	BossPhaseMinions                   // The core's open. It fires fans from the core, and launches kamikaze minions.
+	BossPhaseMeteors                   // Down to half health, it summons meteors as well.
+)
+
+// bossPart is a piece of the boss that can be shot away: its core, or one of its turrets.
+type bossPart struct {
+	offset    Vector         // Where it is, from the middle of the boss.
+	health    int            // Hits it's got left.
+	maxHealth int            // Hits it took to start with.
+	hitFlash  int            // Ticks left of the flash from the last hit.
+	partObj   *resolv.Circle // The collision object.
+}
+
+// newBossPart is a factory method which creates a part of the boss at offset from its middle.
+func newBossPart(offset Vector, radius float64, health int) *bossPart {
+	p := &bossPart{
+		offset:    offset,
+		health:    health,
+		maxHealth: health,
+		partObj:   resolv.NewCircle(0, 0, radius),
+	}
+	p.partObj.Tags().Set(TagBoss)
+
+	return p
+}
+
+// isDestroyed reports whether the part's been shot away.
+func (p *bossPart) isDestroyed() bool {
+	return p.health <= 0
+}
+
+// Boss is the mothership that turns up every few levels. Shots bounce off its hull; its turrets
+// have to be shot away before its core opens up, and destroying the core destroys it. It comes
+// down from the top of the screen, then sweeps from side to side.
+type Boss struct {
+	game        *GameScene            // The current game scene.
+	position    Vector                // Where the middle of it is.
+	sprite      *ebiten.Image         // The hull's image.
+	direction   float64               // Which way it's sweeping: 1 for right, -1 for left.
+	hullObj     *resolv.ConvexPolygon // The hull's collision object.
+	core        *bossPart             // The core.
+	turrets     []*bossPart           // The turrets.
+	fanTimer    *Timer                // The wait between laser fans.
+	minionTimer *Timer                // The wait between minions.
+	summonTimer *Timer                // The wait between summoned meteors.
+	dyingTimer  *Timer                // How long until it's finished blowing up.
+}
+
+// NewBoss is a factory method which creates a boss, just above the top of the screen.
+func NewBoss(g *GameScene) *Boss {
+	t := g.tuning.Boss
+	b := &Boss{
+		game:        g,
+		position:    Vector{X: ScreenWidth / 2, Y: -150},
+		sprite:      assets.BossSprite,
+		direction:   1,
+		hullObj:     resolv.NewConvexPolygon(0, 0, bossHull),
+		core:        newBossPart(bossCoreSpot, bossCoreRadius, t.CoreHealth),
+		fanTimer:    NewTimer(time.Duration(t.FanTime)),
+		minionTimer: NewTimer(time.Duration(t.MinionTime)),
+		summonTimer: NewTimer(time.Duration(t.SummonTime)),
+		dyingTimer:  NewTimer(bossDyingTime),
+	}
+	for _, spot := range bossTurretSpots {
+		b.turrets = append(b.turrets, newBossPart(spot, bossTurretRadius, t.TurretHealth))
+	}
+	b.hullObj.Tags().Set(TagBoss)
+	b.place()
+
+	return b
+}
+
+// place moves the collision objects to where the boss is.
+func (b *Boss) place() {
+	b.hullObj.SetPosition(b.position.X, b.position.Y)
+	for _, p := range b.parts() {
+		c := b.centreOf(p)
+		p.partObj.SetPosition(c.X, c.Y)
+	}
+}
+
+// parts returns the parts that can be shot away: the turrets, then the core.
+func (b *Boss) parts() []*bossPart {
+	return append(slices.Clone(b.turrets), b.core)
+}
+
+// shapes returns the boss's collision objects: its hull, and the parts that are left.
+func (b *Boss) shapes() []resolv.IShape {
+	shapes := []resolv.IShape{b.hullObj}
+	for _, p := range b.parts() {
+		if !p.isDestroyed() {
+			shapes = append(shapes, p.partObj)
+		}
+	}
+	return shapes
+}
+
+// centreOf returns the middle of part p.
+func (b *Boss) centreOf(p *bossPart) Vector {
+	return b.position.Add(p.offset)
+}
+
+// phase returns how the boss is attacking now.
+func (b *Boss) phase() BossPhase {
+	if slices.ContainsFunc(b.turrets, func(p *bossPart) bool { return !p.isDestroyed() }) {
+		return BossPhaseLaserFan
+	}
+	if b.core.health*2 > b.core.maxHealth {
+		return BossPhaseMinions
+	}
+	return BossPhaseMeteors
+}
+
+// isArmoured reports whether part p can't be hurt yet. The core can't while there are turrets left.
+func (b *Boss) isArmoured(p *bossPart) bool {
+	return p == b.core && b.phase() == BossPhaseLaserFan
+}
+
+// isDying reports whether the core's gone, and the boss is blowing up.
+func (b *Boss) isDying() bool {
+	return b.core.isDestroyed()
+}
+
+// isDestroyed reports whether the boss has finished blowing up.
+func (b *Boss) isDestroyed() bool {
+	return b.isDying() && b.dyingTimer.IsReady()
+}
+
+// Update moves the boss, and lets it attack. It's called once per tick.
+func (b *Boss) Update() {
+	for _, p := range b.parts() {
+		if p.hitFlash > 0 {
+			p.hitFlash--
+		}
+	}
+
+	if b.isDying() {
+		// Blasts go off around the hull as it breaks up.
+		b.dyingTimer.Update()
+		if b.dyingTimer.currentTicks%10 == 0 && !b.dyingTimer.IsReady() {
+			spot := bossTurretSpots[(b.dyingTimer.currentTicks/10)%len(bossTurretSpots)]
+			b.game.blasts = append(b.game.blasts, NewBlast(b.position.Add(spot), 70))
+			b.game.audio.Restart(SoundExplosion)
+		}
+		return
+	}
+
+	speed := b.game.tuning.Boss.Speed / TicksPerSecond
+	if b.position.Y < bossTop {
+		// It's still coming in, and doesn't attack until it's there.
+		b.position.Y = min(b.position.Y+speed, bossTop)
+		b.place()
+		return
+	}
+
+	b.position.X += speed * b.direction
+	if b.position.X > ScreenWidth-bossMargin {
+		b.direction = -1
+	} else if b.position.X < bossMargin {
+		b.direction = 1
+	}
+	b.place()
+
+	b.attack()
+}
+
+// attack fires laser fans, launches minions and summons meteors, as the phase calls for.
+func (b *Boss) attack() {
+	g := b.game
+	t := g.tuning.Boss
+	phase := b.phase()
+
+	b.fanTimer.Update()
+	if b.fanTimer.IsReady() {
+		b.fanTimer.Reset()
+		if phase == BossPhaseLaserFan {
+			for _, p := range b.turrets {
+				if !p.isDestroyed() {
+					b.fireFan(b.centreOf(p))
+				}
+			}
+		} else {
+			b.fireFan(b.centreOf(b.core))
+		}
+		g.audio.Play(SoundAlienLaser)
+	}
+
+	if phase >= BossPhaseMinions {
+		b.minionTimer.Update()
+		if b.minionTimer.IsReady() && len(g.aliens) < t.MaxMinions {
+			b.minionTimer.Reset()
+			b.launchMinion()
+		}
+	}
+
+	if phase == BossPhaseMeteors {
+		b.summonTimer.Update()
+		if b.summonTimer.IsReady() && len(g.meteors) < t.MaxMeteors {
+			b.summonTimer.Reset()
+			b.summonMeteor()
+		}
+	}
+}
+
+// fireFan fires a fan of lasers from from, spread evenly either side of the ship.
+func (b *Boss) fireFan(from Vector) {
+	t := b.game.tuning.Boss
+	aim := b.game.aimAt(from)
+	spread := t.FanAngle * math.Pi / 180
+	for i := 0; i < t.FanShots; i++ {
+		offset := 0.0
+		if t.FanShots > 1 {
+			offset = spread * (float64(i)/float64(t.FanShots-1) - 0.5)
+		}
+		b.game.addAlienLaser(from, aim+offset)
+	}
+}
+
+// launchMinion sends a kamikaze alien out of the core.
+func (b *Boss) launchMinion() {
+	g := b.game
+	a := NewAlien(g.tuning.Aliens.BaseVelocity, AlienHunter, BehaviourKamikaze, g)
+	a.position = b.centreOf(b.core)
+	a.movement = Vector{}
+	a.alienObj.SetPosition(a.position.X, a.position.Y)
+	g.addAlien(a)
+}
+
+// summonMeteor brings a medium meteor in from the edge of the screen.
+func (b *Boss) summonMeteor() {
+	g := b.game
+	m := newMeteor(MeteorMedium, g.baseVelocity, EdgeAny, g, len(g.meteors)-1, g.random.meteors)
+	m.material = g.level.material(g.random.meteors)
+	g.addMeteor(m)
+}
+
+// Draw draws the boss: its hull, its turrets with their health, and its core. It fades out as it
+// blows up.
+func (b *Boss) Draw(screen *ebiten.Image) {
+	if b.isDestroyed() {
+		return
+	}
+
+	bounds := b.sprite.Bounds()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-float64(bounds.Dx())/2, -float64(bounds.Dy())/2)
+	op.GeoM.Translate(b.position.X, b.position.Y)
+	if b.isDying() {
+		op.ColorScale.ScaleAlpha(float32(b.dyingTimer.Remaining()))
+	}
+	screen.DrawImage(b.sprite, op)
+
+	if b.isDying() {
+		return
+	}
+
+	ship := b.game.player.centre()
+	for _, p := range b.turrets {
+		c := b.centreOf(p)
+		x, y := float32(c.X), float32(c.Y)
+		if p.isDestroyed() {
+			vector.DrawFilledCircle(screen, x, y, bossTurretRadius, color.RGBA{R: 40, G: 40, B: 40, A: 255}, true)
+			continue
+		}
+
+		// The barrel points at the ship.
+		tip := c.Add(c.Towards(ship, bossTurretRadius+10))
+		vector.StrokeLine(screen, x, y, float32(tip.X), float32(tip.Y), 6, color.RGBA{R: 90, G: 90, B: 100, A: 255}, true)
+		vector.DrawFilledCircle(screen, x, y, bossTurretRadius, partColour(p, color.RGBA{R: 200, G: 60, B: 60, A: 255}), true)
+		drawHealthBar(screen, c.X-20, c.Y-bossTurretRadius-12, 40, 5, p)
+	}
+
+	c := b.centreOf(b.core)
+	if b.isArmoured(b.core) {
+		vector.DrawFilledCircle(screen, float32(c.X), float32(c.Y), bossCoreRadius, color.RGBA{R: 70, G: 60, B: 90, A: 255}, true)
+		vector.StrokeCircle(screen, float32(c.X), float32(c.Y), bossCoreRadius, 4, color.RGBA{R: 150, G: 150, B: 160, A: 255}, true)
+	} else {
+		vector.DrawFilledCircle(screen, float32(c.X), float32(c.Y), bossCoreRadius, partColour(b.core, color.RGBA{R: 90, G: 220, B: 255, A: 255}), true)
+	}
+}
+
+// DrawHealth draws the core's health across the top of the screen. It's grey while the core's
+// armoured.
+func (b *Boss) DrawHealth(screen *ebiten.Image) {
+	if b.isDying() {
+		return
+	}
+	if b.isArmoured(b.core) {
+		vector.StrokeRect(screen, ScreenWidth/2-200, 100, 400, 10, 2, color.RGBA{R: 150, G: 150, B: 160, A: 255}, false)
+		return
+	}
+	drawHealthBar(screen, ScreenWidth/2-200, 100, 400, 10, b.core)
+}
+
+// partColour returns colour, or white if p has just been hit.
+func partColour(p *bossPart, colour color.RGBA) color.RGBA {
+	if p.hitFlash > 0 {
+		return color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	}
+	return colour
+}
+
+// drawHealthBar draws p's health as a bar at x, y, w wide and h high when it's full.
+func drawHealthBar(screen *ebiten.Image, x, y, w, h float64, p *bossPart) {
+	left := float64(p.health) / float64(p.maxHealth)
+	vector.StrokeRect(screen, float32(x), float32(y), float32(w), float32(h), 1, color.RGBA{R: 255, G: 255, B: 255, A: 160}, false)
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(w*left), float32(h), color.RGBA{R: 220, G: 50, B: 50, A: 255}, false)
+}
@@ -0,0 +1,101 @@
+package goasteroids
+
+import "github.com/solarlune/resolv"
+
+// credit is who a hit is put down to. Only the player's own hits score points or drop power-ups.
+type credit int
+
+const (
+	creditPlayer credit = iota // The player's shots and mines, and the blasts they set off.
+	creditNobody               // Alien lasers, aliens flying into things, and the blasts they set off.
+)
+
+// collisionRule says what happens when two sorts of thing touch. A collision object is of the
+// first sort if it has all of first's tags and none of notFirst's, and likewise for the second.
+type collisionRule struct {
+	first, notFirst   resolv.Tags
+	second, notSecond resolv.Tags
+	collide           func(g *GameScene, first, second resolv.IShape) // Called for every pair that touch.
+}
+
+// collisionRules are what happens when things the player has nothing to do with run into each
+// other. They're tried in order, once per tick.
+var collisionRules = []collisionRule{
+	// Alien lasers chip or break meteors, and are used up doing it.
+	{first: TagAlien | TagLaser, second: TagMeteor, collide: (*GameScene).alienLaserHitsMeteor},
+
+	// Aliens that fly into a meteor blow up. The meteor carries on.
+	{first: TagAlien, notFirst: TagLaser, second: TagMeteor, collide: (*GameScene).alienHitsMeteor},
+}
+
+// isTagged reports whether shape has all of the tags in all, and none of those in none.
+func isTagged(shape resolv.IShape, all, none resolv.Tags) bool {
+	tags := *shape.Tags()
+	return tags&all == all && tags&none == 0
+}
+
+// isFirst reports whether shape is of the rule's first sort.
+func (r collisionRule) isFirst(shape resolv.IShape) bool {
+	return isTagged(shape, r.first, r.notFirst)
+}
+
+// isSecond reports whether shape is of the rule's second sort.
+func (r collisionRule) isSecond(shape resolv.IShape) bool {
+	return isTagged(shape, r.second, r.notSecond)
+}
+
+// applyCollisionRules checks every rule against the collision objects in the space. Each object
+// of a rule's first sort is only tested against those of its second sort in the cells around it.
+// A rule goes by the objects there when it starts, so it's up to each rule to skip whatever it's
+// already used up.
+func (g *GameScene) applyCollisionRules() {
+	for _, r := range collisionRules {
+		for _, first := range g.space.FilterShapes().ByTags(r.first).ByFunc(r.isFirst).Shapes() {
+			// The hits are collected first and dealt with after, since dealing with them can move
+			// things about or run tests of its own.
+			var hits []resolv.IShape
+			first.IntersectionTest(resolv.IntersectionTestSettings{
+				TestAgainst: first.SelectTouchingCells(1).FilterShapes().ByFunc(r.isSecond),
+				OnIntersect: func(set resolv.IntersectionSet) bool {
+					hits = append(hits, set.OtherShape)
+					return true
+				},
+			})
+
+			for _, second := range hits {
+				r.collide(g, first, second)
+			}
+		}
+	}
+}
+
+// alienLaserHitsMeteor hits the meteor with the alien laser, which goes.
+func (g *GameScene) alienLaserHitsMeteor(laser, meteor resolv.IShape) {
+	i, ok := g.alienLaserOf(laser)
+	if !ok {
+		return
+	}
+	m, ok := g.meteorOf(meteor)
+	if !ok {
+		return
+	}
+
+	l := g.alienLasers[i]
+	g.space.Remove(l.laserObj)
+	delete(g.alienLasers, i)
+
+	g.hitMeteor(m, l.position, creditNobody)
+}
+
+// alienHitsMeteor blows up the alien that flew into the meteor.
+func (g *GameScene) alienHitsMeteor(alien, meteor resolv.IShape) {
+	a, ok := g.alienOf(alien)
+	if !ok {
+		return
+	}
+	if _, ok := g.meteorOf(meteor); !ok {
+		return
+	}
+
+	g.killAlien(a, creditNobody)
+}
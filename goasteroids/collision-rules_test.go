@@ -0,0 +1,82 @@
+package goasteroids
+
+import (
+	"testing"
+
+	"github.com/solarlune/resolv"
+)
+
+// fireAlienLaserAt fires an alien laser straight up at shape, from a little way below it.
+func fireAlienLaserAt(g *GameScene, shape resolv.IShape) {
+	at := shape.Position()
+	g.addAlienLaser(Vector{X: at.X, Y: at.Y + 120}, 0)
+}
+
+// stepUntil steps g with no input until done reports true, for up to two seconds. It reports
+// whether done ever did.
+func stepUntil(g *GameScene, done func() bool) bool {
+	for range 2 * TicksPerSecond {
+		g.Step(0)
+		if done() {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAlienLaserBreaksMeteorForNothing(t *testing.T) {
+	g := emptyGame(t)
+	m := placeMeteor(g, MeteorLarge, Vector{X: 300, Y: 200})
+	fireAlienLaserAt(g, m.meteorObj)
+
+	if !stepUntil(g, m.isExploding) {
+		t.Fatal("the alien laser never broke the meteor")
+	}
+	if len(g.alienLasers) != 0 {
+		t.Errorf("%d alien lasers still flying, want the one that hit to be used up", len(g.alienLasers))
+	}
+	if pieces := len(g.meteors) - 1; pieces != g.tuning.Meteors.PiecesPerSplit {
+		t.Errorf("meteor broke into %d pieces, want %d", pieces, g.tuning.Meteors.PiecesPerSplit)
+	}
+	if g.Score() != 0 || len(g.powerUps) != 0 {
+		t.Errorf("the player got %d points and %d power-ups for the aliens' kill", g.Score(), len(g.powerUps))
+	}
+}
+
+func TestAlienLaserChipsIronMeteorForNothing(t *testing.T) {
+	g := emptyGame(t)
+	m := placeMeteor(g, MeteorLarge, Vector{X: 300, Y: 200})
+	m.material = MaterialIron
+	fireAlienLaserAt(g, m.meteorObj)
+
+	if !stepUntil(g, func() bool { return m.damage > 0 }) {
+		t.Fatal("the alien laser never hit the meteor")
+	}
+	if m.damage != 1 || m.isExploding() {
+		t.Errorf("iron meteor has %d damage (exploding %v) after one hit, want it chipped", m.damage, m.isExploding())
+	}
+	if g.Score() != 0 {
+		t.Errorf("the player got %d points for the aliens' hit", g.Score())
+	}
+}
+
+func TestAlienFliesIntoMeteorForNothing(t *testing.T) {
+	g := emptyGame(t)
+	m := placeMeteor(g, MeteorLarge, Vector{X: 300, Y: 200})
+
+	a := NewAlien(0, AlienFromLeft, BehaviourCruise, g)
+	at := m.meteorObj.Position()
+	a.position = Vector{X: at.X, Y: at.Y - 100}
+	a.movement = Vector{Y: 4}
+	g.addAlien(a)
+
+	if !stepUntil(g, func() bool { return a.sprite == g.explosionSprite }) {
+		t.Fatal("the alien never blew up")
+	}
+	if m.isExploding() {
+		t.Error("the meteor broke as well, want it to carry on")
+	}
+	if g.Score() != 0 || len(g.powerUps) != 0 {
+		t.Errorf("the player got %d points and %d power-ups for the alien's crash", g.Score(), len(g.powerUps))
+	}
+}
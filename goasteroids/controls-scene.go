@@ -0,0 +1,168 @@
+package goasteroids
+
+import (
+	"asteroids/assets"
+	"image/color"
+	"log"
+	"maps"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// rebindableActions are the actions listed on the controls screen, in order.
+var rebindableActions = []Action{
+	ActionRotateLeft,
+	ActionRotateRight,
+	ActionThrust,
+	ActionReverse,
+	ActionFire,
+	ActionShield,
+	ActionHyperspace,
+	ActionPause,
+	ActionConfirm,
+	ActionCycleWeapon,
+}
+
+// ControlsScene lets the player change which keys trigger each action. Picking an action waits
+// for the next key press and binds it; escape cancels. Changes are saved to the controls file
+// when the player leaves.
+type ControlsScene struct {
+	bindings Bindings // The bindings being edited.
+	menu     *Menu
+	waiting  bool         // Are we waiting for a key for the highlighted action?
+	changed  bool         // Has anything changed since we were opened?
+	keys     []ebiten.Key // Reused every tick to read key presses.
+}
+
+// NewControlsScene creates a controls screen. The bindings are read from the input the first time
+// it's updated.
+func NewControlsScene() *ControlsScene {
+	items := make([]MenuItem, len(rebindableActions)+2)
+	items[len(rebindableActions)].Label = "RESET TO DEFAULTS"
+	items[len(rebindableActions)+1].Label = "BACK"
+
+	return &ControlsScene{menu: NewMenu(items...)}
+}
+
+// Update handles the menu, or waits for a key. It's called once per tick.
+func (c *ControlsScene) Update(state *State) error {
+	if c.bindings == nil {
+		c.bindings = maps.Clone(state.Input.Bindings())
+		c.refresh()
+	}
+
+	if c.waiting {
+		c.keys = inpututil.AppendJustPressedKeys(c.keys[:0])
+		if len(c.keys) == 0 {
+			return nil
+		}
+
+		c.waiting = false
+		if c.keys[0] != ebiten.KeyEscape {
+			c.bindings[rebindableActions[c.menu.Selected()]] = []ebiten.Key{c.keys[0]}
+			c.apply(state)
+		}
+		c.refresh()
+		return nil
+	}
+
+	if state.Input.IsJustPressed(ActionPause) {
+		c.leave(state)
+		return nil
+	}
+
+	choice, ok := c.menu.Update(state.Input)
+	if !ok {
+		return nil
+	}
+
+	switch choice {
+	case len(rebindableActions):
+		c.bindings = DefaultBindings()
+		c.apply(state)
+		c.refresh()
+	case len(rebindableActions) + 1:
+		c.leave(state)
+	default:
+		c.waiting = true
+		c.refresh()
+	}
+
+	return nil
+}
+
+// apply hands the edited bindings to the input, so they work straight away.
+func (c *ControlsScene) apply(state *State) {
+	state.Input.SetBindings(maps.Clone(c.bindings))
+	c.changed = true
+}
+
+// refresh updates the menu to show the keys bound to each action.
+func (c *ControlsScene) refresh() {
+	for i, a := range rebindableActions {
+		keys := "PRESS A KEY"
+		if !c.waiting || i != c.menu.Selected() {
+			var names []string
+			for _, k := range c.bindings[a] {
+				names = append(names, strings.ToUpper(k.String()))
+			}
+			keys = strings.Join(names, " / ")
+		}
+		c.menu.SetLabel(i, actionLabel(a)+"  "+keys)
+	}
+}
+
+// actionLabel returns the name of action a as shown on screen.
+func actionLabel(a Action) string {
+	return strings.ToUpper(strings.ReplaceAll(a.String(), "-", " "))
+}
+
+// leave saves the bindings, if they changed, and goes back to the options.
+func (c *ControlsScene) leave(state *State) {
+	if c.changed {
+		if err := SaveBindings(c.bindings); err != nil {
+			log.Println("Error saving controls:", err)
+		}
+	}
+	state.SceneManager.PopScene(pauseTransition)
+}
+
+// Draw covers the screen and draws the list of actions. It's called once per frame.
+func (c *ControlsScene) Draw(screen *ebiten.Image) {
+	vector.DrawFilledRect(screen, 0, 0, ScreenWidth, ScreenHeight, color.Black, false)
+
+	op := &text.DrawOptions{
+		LayoutOptions: text.LayoutOptions{
+			PrimaryAlign: text.AlignCenter,
+		},
+	}
+	op.ColorScale.ScaleWithColor(color.White)
+	op.GeoM.Translate(ScreenWidth/2, 80)
+	text.Draw(screen, "CONTROLS", &text.GoTextFace{
+		Source: assets.TitleFont,
+		Size:   48,
+	}, op)
+
+	c.menu.Draw(screen, ScreenWidth/2, 200)
+
+	textToDraw := "CONFIRM TO CHANGE A KEY"
+	if c.waiting {
+		textToDraw = "PRESS A KEY, OR ESCAPE TO CANCEL"
+	}
+	op = &text.DrawOptions{
+		LayoutOptions: text.LayoutOptions{
+			PrimaryAlign: text.AlignCenter,
+		},
+	}
+	op.ColorScale.ScaleWithColor(color.White)
+	op.ColorScale.ScaleAlpha(0.5)
+	op.GeoM.Translate(ScreenWidth/2, ScreenHeight-80)
+	text.Draw(screen, textToDraw, &text.GoTextFace{
+		Source: assets.ScoreFont,
+		Size:   12,
+	}, op)
+}
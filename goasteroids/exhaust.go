@@ -13,9 +13,10 @@ type Exhaust struct {
 	position Vector
 	rotation float64
 	sprite   *ebiten.Image
+	speed    float64 // The ship's top speed, so the flame keeps up with it.
 }
 
-func NewExhaust(pos Vector, rotation float64) *Exhaust {
+func NewExhaust(pos Vector, rotation, speed float64) *Exhaust {
 	sprite := assets.ExhaustSprite
 
 	bounds := sprite.Bounds()
@@ -29,6 +30,7 @@ func NewExhaust(pos Vector, rotation float64) *Exhaust {
 		position: pos,
 		rotation: rotation,
 		sprite: sprite,
+		speed: speed,
 	}
 }
 
@@ -47,7 +49,7 @@ func (e *Exhaust) Draw(screen *ebiten.Image) {
 }
 
 func (e *Exhaust) Update() {
-	speed := maxAcceleration / float64(ebiten.TPS())
+	speed := e.speed / TicksPerSecond
 	e.position.X += math.Sin(e.rotation) * speed
 	e.position.Y += math.Cos(e.rotation) * -speed
 }
@@ -0,0 +1,365 @@
+package goasteroids
+
+import (
+	"asteroids/assets"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/solarlune/resolv"
+)
+
+const (
+	formationsVersion   = 1               // Bump this when the layout of the formations file changes.
+	formationSwayPeriod = 4 * time.Second // How long a formation takes to sway there and back.
+	formationJoinRange  = 40              // How close a regrouping alien gets before it flies straight into its slot.
+	formationDiveRange  = 60              // How close a diving alien gets to where the ship was before it pulls out.
+)
+
+// FormationShape is how a formation's aliens are arranged once they've flown in.
+type FormationShape string
+
+const (
+	ShapeV      FormationShape = "v"      // A V, pointing down the screen.
+	ShapeLine   FormationShape = "line"   // A row across the screen.
+	ShapeCircle FormationShape = "circle" // A ring that turns as it goes.
+)
+
+// FormationSet is the formations alien schedules can send, from assets/data/formations.json.
+type FormationSet struct {
+	Version    int         `json:"version"`
+	Formations []Formation `json:"formations"`
+}
+
+// Formation is a squad of aliens that fly in one after the other along a curved path, take up
+// their places in a shape at the end of it, and then take turns breaking off to dive at the ship
+// and coming back.
+type Formation struct {
+	Name     string         `json:"name"`
+	Shape    FormationShape `json:"shape"`
+	Count    int            `json:"count"`            // How many aliens fly in it.
+	Spacing  float64        `json:"spacing"`          // Pixels between neighbours, in the shape and along the path.
+	Path     []Vector       `json:"path"`             // The points the path curves through: in from off the screen, to where the formation holds.
+	Mirror   bool           `json:"mirror,omitempty"` // Half the time it comes in flipped left to right.
+	Speed    float64        `json:"speed"`            // Pixels per second, along the path and diving.
+	Sway     float64        `json:"sway,omitempty"`   // Pixels either way the formation drifts while it holds.
+	Spin     float64        `json:"spin,omitempty"`   // Degrees per second a circle turns.
+	DiveTime Duration       `json:"diveTime"`         // The wait between aliens breaking off to dive.
+}
+
+var builtInFormations = mustBuiltInFormations() // The formations that ship with the game.
+
+// mustBuiltInFormations reads the formations in assets/data/formations.json. A broken file is a
+// bug, so it panics, like a missing asset does.
+func mustBuiltInFormations() []Formation {
+	s, err := parseFormations(assets.FormationData)
+	if err != nil {
+		panic(fmt.Sprintf("built-in formations: %v", err))
+	}
+	return s.Formations
+}
+
+// findFormation returns the formation called name, if there is one.
+func findFormation(name string) (*Formation, bool) {
+	for i := range builtInFormations {
+		if builtInFormations[i].Name == name {
+			return &builtInFormations[i], true
+		}
+	}
+	return nil, false
+}
+
+// formationNames returns the names of the formations, in the order they're in the file.
+func formationNames() []string {
+	var names []string
+	for _, f := range builtInFormations {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// parseFormations reads and checks a formations file.
+func parseFormations(data []byte) (*FormationSet, error) {
+	var s FormationSet
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields() // A typo shouldn't silently leave a value at its default.
+	if err := d.Decode(&s); err != nil {
+		return nil, err
+	}
+	if s.Version != formationsVersion {
+		return nil, fmt.Errorf("unsupported version %d", s.Version)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Validate checks that every formation makes sense, and says what doesn't if it doesn't.
+func (s *FormationSet) Validate() error {
+	var errs []error
+	for i, f := range s.Formations {
+		fail := func(format string, args ...any) {
+			errs = append(errs, fmt.Errorf("formations[%d].%s", i, fmt.Sprintf(format, args...)))
+		}
+
+		if f.Name == "" {
+			fail("name is missing")
+		}
+		if slices.IndexFunc(s.Formations, func(o Formation) bool { return o.Name == f.Name }) < i {
+			fail("name: there's already a formation called %q", f.Name)
+		}
+		if !slices.Contains([]FormationShape{ShapeV, ShapeLine, ShapeCircle}, f.Shape) {
+			fail("shape: unknown shape %q", f.Shape)
+		}
+		if f.Count < 1 {
+			fail("count must be at least 1, not %d", f.Count)
+		}
+		if f.Spacing <= 0 {
+			fail("spacing must be more than 0, not %v", f.Spacing)
+		}
+		if len(f.Path) < 2 {
+			fail("path: there must be at least two points")
+		}
+		for j, p := range f.Path {
+			// Aliens further off than this are cleared away, so the path can't go there.
+			if p.X < -150 || p.X > ScreenWidth+150 || p.Y < -150 || p.Y > ScreenHeight+150 {
+				fail("path[%d] (%v, %v) is too far off the screen", j, p.X, p.Y)
+			}
+		}
+		if n := len(f.Path); n > 0 && (f.Path[n-1].X < 0 || f.Path[n-1].X > ScreenWidth || f.Path[n-1].Y < 0 || f.Path[n-1].Y > ScreenHeight) {
+			fail("path: the last point, where it holds, must be on the screen")
+		}
+		if f.Speed <= 0 {
+			fail("speed must be more than 0, not %v", f.Speed)
+		}
+		if f.Sway < 0 {
+			fail("sway can't be negative, not %v", f.Sway)
+		}
+		if time.Duration(f.DiveTime) < time.Second/TicksPerSecond {
+			fail("diveTime must be at least one tick (%v), not %v", time.Second/TicksPerSecond, time.Duration(f.DiveTime))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// slot returns where alien i sits in the formation, from its middle, when it's turned by spin
+// radians.
+func (f *Formation) slot(i int, spin float64) Vector {
+	switch f.Shape {
+	case ShapeV:
+		// The first at the point, and the rest alternately up the left and right arms.
+		k := float64((i + 1) / 2)
+		side := 1.0
+		if i%2 == 1 {
+			side = -1
+		}
+		return Vector{X: side * k * f.Spacing, Y: -k * f.Spacing}
+	case ShapeCircle:
+		// Spread round a ring that's just big enough to keep them Spacing apart.
+		radius := f.Spacing * float64(f.Count) / (2 * math.Pi)
+		angle := 2*math.Pi*float64(i)/float64(f.Count) + spin
+		return Vector{X: math.Cos(angle) * radius, Y: math.Sin(angle) * radius}
+	}
+	return Vector{X: (float64(i) - float64(f.Count-1)/2) * f.Spacing}
+}
+
+// flight is what an alien in a formation is up to.
+type flight int
+
+const (
+	flightEntering   flight = iota // Following the path in.
+	flightJoining                  // Flying straight into its slot.
+	flightHolding                  // Sitting in its slot.
+	flightDiving                   // Broken off, and diving at the ship.
+	flightRegrouping               // Pulled out of a dive, and heading back.
+)
+
+// Squadron is a formation being flown.
+type Squadron struct {
+	game      *GameScene
+	formation *Formation
+	path      *spline
+	members   []*Alien // The aliens still flying in it.
+	travelled float64  // How far along the path the first alien is.
+	ticks     int      // How long it's been flying, for swaying and turning.
+	diveTimer *Timer   // When the next alien breaks off to dive.
+}
+
+// NewSquadron is a factory method which creates a squadron flying formation f, with its aliens
+// lined up at the start of the path.
+func NewSquadron(f *Formation, g *GameScene) *Squadron {
+	path := slices.Clone(f.Path)
+	if f.Mirror && g.random.aliens.IntN(2) == 0 {
+		for i := range path {
+			path[i].X = ScreenWidth - path[i].X
+		}
+	}
+
+	q := &Squadron{
+		game:      g,
+		formation: f,
+		path:      newSpline(path),
+		diveTimer: NewTimer(time.Duration(f.DiveTime)),
+	}
+
+	// They all look the same.
+	sprite := assets.AlienSprites[g.random.aliens.IntN(len(assets.AlienSprites))]
+	for i := 0; i < f.Count; i++ {
+		q.members = append(q.members, newSquadronAlien(q, i, sprite))
+	}
+	return q
+}
+
+// newSquadronAlien creates the alien that flies in slot of squadron q.
+func newSquadronAlien(q *Squadron, slot int, sprite *ebiten.Image) *Alien {
+	pos := q.path.at(0)
+	a := &Alien{
+		game:          q.game,
+		sprite:        sprite,
+		position:      pos,
+		alienObj:      resolv.NewCircle(pos.X, pos.Y, float64(sprite.Bounds().Dx()/2)),
+		isIntelligent: true,
+		squadron:      q,
+		slot:          slot,
+		flight:        flightEntering,
+	}
+	a.alienObj.Tags().Set(TagAlien)
+	return a
+}
+
+// Update lets go of the aliens that have been shot down or have flown off, moves the formation
+// along, and now and then sends one of the aliens in it diving at the ship. It's called once per
+// tick, before the aliens are moved.
+func (q *Squadron) Update() {
+	g := q.game
+	about := make(map[*Alien]bool, len(g.aliens))
+	for _, a := range g.aliens {
+		about[a] = true
+	}
+	q.members = slices.DeleteFunc(q.members, func(a *Alien) bool {
+		gone := !about[a] || a.sprite == g.explosionSprite
+		if gone {
+			a.squadron = nil
+		}
+		return gone
+	})
+
+	q.ticks++
+	q.travelled += q.formation.Speed / TicksPerSecond
+
+	q.diveTimer.Update()
+	if q.diveTimer.IsReady() {
+		var holding []*Alien
+		for _, a := range q.members {
+			if a.flight == flightHolding {
+				holding = append(holding, a)
+			}
+		}
+		if len(holding) > 0 {
+			q.diveTimer.Reset()
+			a := holding[g.random.aliens.IntN(len(holding))]
+			a.flight = flightDiving
+			a.diveTarget = g.player.centre()
+		}
+	}
+}
+
+// isOver reports whether every alien in the squadron is gone.
+func (q *Squadron) isOver() bool {
+	return len(q.members) == 0
+}
+
+// slotPosition returns where the alien in slot is meant to be, now.
+func (q *Squadron) slotPosition(slot int) Vector {
+	f := q.formation
+	seconds := float64(q.ticks) / TicksPerSecond
+
+	centre := q.path.at(q.path.length())
+	centre.X += math.Sin(2*math.Pi*seconds/formationSwayPeriod.Seconds()) * f.Sway
+	return centre.Add(f.slot(slot, f.Spin*math.Pi/180*seconds))
+}
+
+// fly sets the movement of a, one of the squadron's aliens, for what it's up to.
+func (q *Squadron) fly(a *Alien) {
+	speed := q.formation.Speed / TicksPerSecond
+	agility := q.game.tuning.Aliens.Agility / TicksPerSecond / TicksPerSecond
+	slot := q.slotPosition(a.slot)
+
+	switch a.flight {
+	case flightEntering:
+		// Each alien follows the one in front, Spacing behind it.
+		d := q.travelled - float64(a.slot)*q.formation.Spacing
+		a.movement = q.path.at(d).Sub(a.position)
+		if d >= q.path.length() {
+			a.flight = flightJoining
+		}
+	case flightJoining:
+		a.movement = slot.Sub(a.position).Limit(speed)
+		if a.position.Distance(slot) <= speed {
+			a.flight = flightHolding
+		}
+	case flightHolding:
+		a.movement = slot.Sub(a.position)
+	case flightDiving:
+		want := seek(a.position, a.diveTarget, speed)
+		a.movement = a.movement.Add(want.Sub(a.movement).Limit(agility))
+
+		// Pull out once it's close, or it's gone past.
+		to := a.diveTarget.Sub(a.position)
+		past := a.movement.Length() > speed/2 && to.X*a.movement.X+to.Y*a.movement.Y < 0
+		if a.position.Distance(a.diveTarget) < formationDiveRange || past {
+			a.flight = flightRegrouping
+		}
+	case flightRegrouping:
+		want := seek(a.position, slot, speed)
+		a.movement = a.movement.Add(want.Sub(a.movement).Limit(agility))
+		if a.position.Distance(slot) < formationJoinRange {
+			a.flight = flightJoining
+		}
+	}
+}
+
+// launchSquadron sends formation f in.
+func (g *GameScene) launchSquadron(f *Formation) {
+	q := NewSquadron(f, g)
+	for _, a := range q.members {
+		g.addAlien(a)
+	}
+	g.squadrons = append(g.squadrons, q)
+}
+
+// updateSquadrons moves the formations along, and lets go of the ones that are gone.
+func (g *GameScene) updateSquadrons() {
+	for _, q := range g.squadrons {
+		q.Update()
+	}
+	g.squadrons = slices.DeleteFunc(g.squadrons, (*Squadron).isOver)
+}
+
+// squadronsFlying returns how many formations called name are flying.
+func (g *GameScene) squadronsFlying(name string) int {
+	n := 0
+	for _, q := range g.squadrons {
+		if q.formation.Name == name {
+			n++
+		}
+	}
+	return n
+}
+
+// loneAliens returns how many aliens are about that aren't flying in a formation.
+func (g *GameScene) loneAliens() int {
+	n := 0
+	for _, a := range g.aliens {
+		if a.squadron == nil {
+			n++
+		}
+	}
+	return n
+}
@@ -2,6 +2,7 @@ package goasteroids
 
 import (
 	"asteroids/assets"
+	"fmt"
 	"image/color"
 	"os"
 
@@ -41,7 +42,37 @@ func (o *GameOverScene) Draw(screen *ebiten.Image) {
 		Size:   48,
 	}, op)
 
-	if o.game.score > originalHighScore {
+	// Draw the seed, so a game can be played again (or reported as a bug).
+	textToDraw = fmt.Sprintf("SEED %d", o.game.Seed())
+	op = &text.DrawOptions{
+		LayoutOptions: text.LayoutOptions{
+			PrimaryAlign: text.AlignCenter,
+		},
+	}
+	op.ColorScale.ScaleWithColor(color.White)
+	op.GeoM.Translate(ScreenWidth/2, ScreenHeight-40)
+	text.Draw(screen, textToDraw, &text.GoTextFace{
+		Source: assets.ScoreFont,
+		Size:   16,
+	}, op)
+
+	textToDraw = "R to watch the replay"
+	if wantsInitials(o.game.score) {
+		textToDraw = "Space to enter your initials   R to watch the replay"
+	}
+	op = &text.DrawOptions{
+		LayoutOptions: text.LayoutOptions{
+			PrimaryAlign: text.AlignCenter,
+		},
+	}
+	op.ColorScale.ScaleWithColor(color.White)
+	op.GeoM.Translate(ScreenWidth/2, ScreenHeight-70)
+	text.Draw(screen, textToDraw, &text.GoTextFace{
+		Source: assets.ScoreFont,
+		Size:   16,
+	}, op)
+
+	if o.game.score > highScores.Best() {
 		textToDraw = "New High Score!"
 		op = &text.DrawOptions{
 			LayoutOptions: text.LayoutOptions{
@@ -60,7 +91,7 @@ func (o *GameOverScene) Draw(screen *ebiten.Image) {
 func (o *GameOverScene) Update(state *State) error {
 	// Spawn meteors.
 	if len(o.meteors) < 10 {
-		m := NewMeteor(0.25, &GameScene{}, len(o.meteors)-1)
+		m := NewMeteor(0.25, EdgeAny, &GameScene{}, len(o.meteors)-1, menuRand)
 		o.meteorCount++
 		o.meteors[o.meteorCount] = m
 	}
@@ -70,14 +101,31 @@ func (o *GameOverScene) Update(state *State) error {
 		m.Update()
 	}
 
-	// Check to see if spacebar pressed.
-	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-		o.game.Reset()
-		state.SceneManager.GoToScene(o.game)
+	// Check to see if spacebar pressed. If the score made the high score table (or there's a shared
+	// leaderboard), the player gets to put their initials in it. Otherwise it's a new game, with a
+	// new seed unless the player picked one.
+	if state.Input.IsJustPressed(ActionConfirm) {
+		if wantsInitials(o.game.score) {
+			state.SceneManager.GoToScene(NewInitialsEntryScene(o.game))
+		} else {
+			state.SceneManager.GoToScene(o.game.nextGame())
+		}
 	}
 
-	// Check to see if q is pressed.
+	// Check to see if r is pressed, to watch the game again. A score that made the table is kept
+	// under the initials entered last time, since there's no coming back here afterwards.
+	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+		if wantsInitials(o.game.score) {
+			recordScore(o.game, lastInitials)
+		}
+		state.SceneManager.GoToScene(NewReplayScene(o.game.replay))
+	}
+
+	// Check to see if q is pressed. Again, a score that made the table isn't lost.
 	if inpututil.IsKeyJustPressed(ebiten.KeyQ) {
+		if wantsInitials(o.game.score) {
+			recordScore(o.game, lastInitials)
+		}
 		os.Exit(0)
 	}
 
@@ -6,26 +6,26 @@ import (
 	"image/color"
 	"log"
 	"math"
-	"math/rand/v2"
+	"slices"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/solarlune/resolv"
 )
 
 const (
-	baseMeteorVelocity   = 0.25                    // The base speed for meteors.
-	meteorSpawnTime      = 100 * time.Millisecond  // How long before meteors spawn.
-	meteorSpeedUpAmount  = 0.1                     // How much do we speed a meteor up when it's timer runs out.
-	meteorSpeedUpTime    = 1000 * time.Millisecond // How long to wait to speed up meteors.
-	cleanUpExplosionTime = 200 * time.Millisecond  // The time to wait for cleaning up explosions.
-	baseBeatWaitTime     = 1600                    // Base number of milliseconds to wait between beats of background. This is an int because we do math on it.
-	numberOfStars        = 1000                    // The number of stars to display on the background.
-	alienAttackTime      = 3 * time.Second         // The time between alien attacks.
-	alienSpawnTime       = 12 * time.Second        // The time between alien spawns.
-	baseAlienVelocity    = 0.5                     // The base velocity for aliens.
+	cleanUpExplosionTime = 200 * time.Millisecond // The time to wait for cleaning up explosions.
+	numberOfStars        = 1000                   // The number of stars to display on the background.
+)
+
+// Phase is where a game is up to: playing a level, waiting for the next one, or finished.
+type Phase int
+
+const (
+	PhasePlaying       Phase = iota // A level is in progress.
+	PhaseLevelStarting              // The last level was cleared and the next one is about to start.
+	PhaseGameOver                   // The player has run out of lives.
 )
 
 // GameScene is the overall type for a game scene (e.g. TitleScene, GameScene, etc.).
@@ -35,116 +35,383 @@ type GameScene struct {
 	meteorCount          int                 // The counter for meteors.
 	meteorSpawnTimer     *Timer              // The timer for spawning meteors.
 	meteors              map[int]*Meteor     // A map of meteors.
-	meteorsForLevel      int                 // # of meteors for a level.
 	velocityTimer        *Timer              // The timer used for speeding up meteors.
 	space                *resolv.Space       // The space for all collision objects.
-	lasers               map[int]*Laser      // A map of lasers.
-	laserCount           int                 // A count of lasers currently in play; used as index for map lasers.
+	projectiles          map[int]Projectile  // A map of everything the player's weapons have fired.
+	projectileCount      int                 // A count of projectiles fired; used as index for map projectiles.
 	score                int                 // Current score.
-	explosionSmallSprite *ebiten.Image       // A small explosion object.
 	explosionSprite      *ebiten.Image       // A large explosion object.
 	explosionFrames      []*ebiten.Image     // The frames for explosion animation.
 	cleanUpTimer         *Timer              // Timer to clean up objects.
 	playerIsDead         bool                // Is the player dead.
-	audioContext         *audio.Context      // The context used for our audio players.
-	thrustPlayer         *audio.Player       // The audio player for thrust sound.
+	audio                Audio               // Where the game's sounds go. Silence when running headless.
 	exhaust              *Exhaust            // The object for exhaust (while accelerating).
-	laserOnePlayer       *audio.Player       // The audio player for laser 1.
-	laserTwoPlayer       *audio.Player       // The audio player for laser 2.
-	laserThreePlayer     *audio.Player       // The audio player for laser 3.
-	explosionPlayer      *audio.Player       // The explosion sound player.
-	beatOnePlayer        *audio.Player       // The audio player for beat one (background sounds).
-	beatTwoPlayer        *audio.Player       // The audio player for beat two sound (background sounds).
 	beatTimer            *Timer              // The time for playing beats one and two.
-	beatWaitTime         int                 // The time to wait between beats. Reduced over time in each level.
+	beatWaitTime         time.Duration       // The time to wait between beats. Reduced over time in each level.
 	playBeatOne          bool                // Should we play beat one? Yes, if true, otherwise play beat two.
 	stars                []*Star             // The stars for background.
 	currentLevel         int                 // The current level the player is on.
 	shield               *Shield             // The player's shield.
-	shieldsUpPlayer      *audio.Player       // The audio player for shields up sound.
 	alienAttackTimer     *Timer              // The timer for alien attacks.
 	alienCount           int                 // The count of aliens.
 	alienLaserCount      int                 // The count of alien lasers.
-	alienLaserPlayer     *audio.Player       // The audio player for alien laser sound.
 	alienLasers          map[int]*AlienLaser // A map of alien lasers.
-	alienSoundPlayer     *audio.Player       // The audio player for alien sound.
-	alienSpawnTimer      *Timer              // The timer for alien spawns.
+	blasts               []*Blast            // The blasts from explosive meteors, while they're shown.
+	powerUps             map[int]*PowerUp    // The power-ups waiting to be picked up.
+	powerUpCount         int                 // A count of power-ups; used as index for map powerUps.
 	aliens               map[int]*Alien      // A map of aliens.
+	squadrons            []*Squadron         // The formations of aliens flying.
+	sightings            []sighting          // What the aliens have seen of the ship lately, newest last.
+	random               *RandomStreams      // Every random choice in the game comes from one of these.
+	input                InputFrame          // The actions held down this tick.
+	lastInput            InputFrame          // The actions held down last tick.
+	phase                Phase               // Are we playing, between levels, or done?
+	nextLevelTimer       *Timer              // The wait between levels.
+	seedFixed            bool                // Was the seed chosen by the player (e.g. the daily challenge)? If so, restarts reuse it.
+	mode                 Mode                // The kind of game, for the high score table.
+	replay               *Replay             // The recording of this game so far.
+	difficulty           Difficulty          // How hard the game pushes the player. Fixed for the whole game.
+	tuning               *Tuning             // The numbers the game is played with.
+	levels               *LevelSet           // The levels being played, in order.
+	level                Level               // The level being played, with the tuning filled in where it leaves things out.
+	wave                 int                 // Which of the level's waves is being played.
+	waveSpawned          int                 // How many of the wave's meteors have been sent.
+	waveTimer            *Timer              // The pause before the wave's first meteor.
+	extraMeteors         int                 // Meteors added to every level by skipping the pause between levels.
+	alienTimers          []*Timer            // One per alien schedule in the level.
+	stats                levelStats          // What the player has done on this level, for its bonus.
+	bonuses              []*Bonus            // The bonuses earned on the last level.
+	boss                 *Boss               // The boss, on boss levels.
+	editor               *LevelEditorScene   // If set, this is a test run from the level editor, which we go back to when it's over.
 }
 
 // NewGameScene is a factory method for producing a new game. It's called once,
-// when game play starts (and again when game play restarts).
-func NewGameScene() *GameScene {
+// when game play starts (and again when game play restarts). The same seed always
+// brings the same waves. It's played at the difficulty and with the levels picked
+// in the options.
+func NewGameScene(seed uint64) *GameScene {
+	return newGameScene(seed, settings.Difficulty, chosenLevelSet(), defaultSpeakers())
+}
+
+// NewHeadlessGameScene creates a game that makes no sound and never needs a window. Drive it
+// with Step; the same seed, difficulty, levels and input frames always play out the same way.
+// It still links ebiten, so building it needs ebiten's C dependencies (see the README).
+func NewHeadlessGameScene(seed uint64, d Difficulty, levels *LevelSet) *GameScene {
+	return newGameScene(seed, d, levels, Silence{})
+}
+
+// chosenLevelSet returns the level set picked in the options, or the classic levels if it can't
+// be found.
+func chosenLevelSet() *LevelSet {
+	levels, err := FindLevelSet(settings.LevelSet)
+	if err != nil {
+		log.Println("Error loading levels, playing the classic ones:", err)
+		return classicLevels()
+	}
+	return levels
+}
+
+func newGameScene(seed uint64, d Difficulty, levels *LevelSet, a Audio) *GameScene {
+	t := tuning
 	g := &GameScene{
-		meteorSpawnTimer:     NewTimer(meteorSpawnTime),
-		baseVelocity:         baseMeteorVelocity,
-		velocityTimer:        NewTimer(meteorSpeedUpTime),
 		meteors:              make(map[int]*Meteor),
 		meteorCount:          0,
-		meteorsForLevel:      2,
 		space:                resolv.NewSpace(ScreenWidth, ScreenHeight, 16, 16),
-		lasers:               make(map[int]*Laser),
-		laserCount:           0,
+		projectiles:          make(map[int]Projectile),
+		projectileCount:      0,
 		explosionSprite:      assets.ExplosionSprite,
-		explosionSmallSprite: assets.ExplosionSmallSprite,
 		cleanUpTimer:         NewTimer(cleanUpExplosionTime),
 		beatTimer:            NewTimer(2 * time.Second),
-		beatWaitTime:         baseBeatWaitTime,
+		beatWaitTime:         time.Duration(t.Levels.BaseBeatWait),
 		currentLevel:         1,
 		aliens:               make(map[int]*Alien),
 		alienCount:           0,
 		alienLasers:          make(map[int]*AlienLaser),
+		powerUps:             make(map[int]*PowerUp),
 		alienLaserCount:      0,
-		alienSpawnTimer:      NewTimer(alienSpawnTime),
-		alienAttackTimer:     NewTimer(alienAttackTime),
+		alienAttackTimer:     NewTimer(alienAttackTime(t, d)),
+		random:               NewRandomStreams(seed),
+		audio:                a,
+		phase:                PhasePlaying,
+		nextLevelTimer:       NewTimer(time.Duration(t.Levels.StartTime)),
+		replay:               NewReplay(seed, d, t.Fingerprint(), levels.Name),
+		mode:                 ModeClassic,
+		difficulty:           d,
+		tuning:               t,
+		levels:               levels,
 	}
 	g.player = NewPlayer(g)
 	g.space.Add(g.player.playerObj)
-	g.stars = GenerateStars(numberOfStars)
+	g.stars = GenerateStars(starCount(), g.random.stars)
+	g.beginLevel()
 
 	g.explosionFrames = assets.Explosion
 
-	// Load audio.
-	g.audioContext = audio.NewContext(48000)
+	return g
+}
 
-	thrustPlayer, _ := g.audioContext.NewPlayer(assets.ThrustSound)
-	g.thrustPlayer = thrustPlayer
+// Update moves the game on by one tick using the player's input, and changes scenes when a level
+// is finished or the game is over. It's called once per tick.
+func (g *GameScene) Update(state *State) error {
+	// Pick up tuning the designers have reloaded.
+	if g.tuning != tuning {
+		g.useTuning(tuning)
+	}
 
-	laserOnePlayer, _ := g.audioContext.NewPlayer(assets.LaserOneSound)
-	g.laserOnePlayer = laserOnePlayer
+	// A test run from the level editor goes back to it when the level's cleared, the game's over,
+	// or the designer's seen enough.
+	if g.editor != nil && (g.phase != PhasePlaying || state.Input.IsJustPressed(ActionPause)) {
+		g.audio.Pause(SoundThrust)
+		g.audio.Pause(SoundAlien)
+		state.SceneManager.GoToScene(g.editor)
+		return nil
+	}
 
-	laserTwoPlayer, _ := g.audioContext.NewPlayer(assets.LaserTwoSound)
-	g.laserTwoPlayer = laserTwoPlayer
+	// Pausing isn't part of the game itself (or its replay), so we check for it before stepping.
+	if state.Input.IsJustPressed(ActionPause) {
+		state.SceneManager.PushScene(NewPauseScene(g), pauseTransition)
+		return nil
+	}
 
-	laserThreePlayer, _ := g.audioContext.NewPlayer(assets.LaserThreeSound)
-	g.laserThreePlayer = laserThreePlayer
+	g.Step(state.Input.Frame())
 
-	explosionPlayer, _ := g.audioContext.NewPlayer(assets.ExplosionSound)
-	g.explosionPlayer = explosionPlayer
+	switch g.phase {
+	case PhaseLevelStarting:
+		state.SceneManager.GoToScene(&LevelStartsScene{
+			game:  g,
+			stars: GenerateStars(starCount(), menuRand),
+		})
+	case PhaseGameOver:
+		// Keep the replay, so the game can be watched again.
+		if _, err := SaveReplay(g.replay); err != nil {
+			log.Println("Error saving replay:", err)
+		}
 
-	beatOnePlayer, _ := g.audioContext.NewPlayer(assets.BeatOneSound)
-	g.beatOnePlayer = beatOnePlayer
+		state.SceneManager.GoToScene(&GameOverScene{
+			game:        g,
+			meteors:     make(map[int]*Meteor),
+			meteorCount: 5,
+			stars:       GenerateStars(starCount(), menuRand),
+		})
+	}
 
-	beatTwoPlayer, _ := g.audioContext.NewPlayer(assets.BeatTwoSound)
-	g.beatTwoPlayer = beatTwoPlayer
+	return nil
+}
 
-	shieldsUpPlayer, _ := g.audioContext.NewPlayer(assets.ShieldSound)
-	g.shieldsUpPlayer = shieldsUpPlayer
+// Step moves the game on by one tick, with frame as the player's input. It doesn't read the
+// keyboard or draw anything, and sounds only go out through g.audio, so it runs the same with or
+// without a window.
+func (g *GameScene) Step(frame InputFrame) {
+	g.lastInput = g.input
+	g.input = frame
+	g.replay.Frames = append(g.replay.Frames, frame)
+
+	switch g.phase {
+	case PhasePlaying:
+		g.stepLevel()
+	case PhaseLevelStarting:
+		g.stepLevelStart()
+	}
+}
 
-	alienLaserPlayer, _ := g.audioContext.NewPlayer(assets.AlienLaserSound)
-	g.alienLaserPlayer = alienLaserPlayer
+// startingVelocity returns the speed meteors start the level at.
+func (g *GameScene) startingVelocity() float64 {
+	return g.level.Speed.Base * g.difficulty.meteorSpeed()
+}
 
-	alienSoundPlayer, _ := g.audioContext.NewPlayer(assets.AlienSound)
-	alienSoundPlayer.SetVolume(0.5)
-	g.alienSoundPlayer = alienSoundPlayer
+// alienAttackTime returns the time between alien attacks with tuning t at difficulty d.
+func alienAttackTime(t *Tuning, d Difficulty) time.Duration {
+	return time.Duration(float64(t.Aliens.AttackTime) * d.alienAttackScale())
+}
 
-	return g
+// useTuning switches a game in progress over to tuning t, which the designers have just
+// reloaded. Timers are started again with their new lengths. A replay can't be played back across
+// a change of numbers, so the replay is marked as having no particular tuning.
+func (g *GameScene) useTuning(t *Tuning) {
+	if t.Fingerprint() != g.tuning.Fingerprint() {
+		g.replay.Tuning = 0
+	}
+	g.tuning = t
+
+	g.alienAttackTimer = NewTimer(alienAttackTime(t, g.difficulty))
+	g.nextLevelTimer = NewTimer(time.Duration(t.Levels.StartTime))
+	g.player.armWeapons(t)
+
+	// Between levels, the next level picks the new numbers up when it starts.
+	if g.phase == PhaseLevelStarting {
+		return
+	}
+	g.level = g.levels.level(g.currentLevel, g.extraMeteors, t)
+	g.meteorSpawnTimer = NewTimer(time.Duration(g.currentWave().SpawnTime))
+	g.velocityTimer = NewTimer(time.Duration(g.level.Speed.Every))
+	g.alienTimers = newAlienTimers(g.level.Aliens)
 }
 
-// Update updates all game scene elements for the next draw. It's called once per tick.
-func (g *GameScene) Update(state *State) error {
+// nextGame creates the game to play after this one: with a new seed, unless the player picked one.
+func (g *GameScene) nextGame() *GameScene {
+	seed := NewSeed()
+	if g.seedFixed {
+		seed = g.Seed()
+	}
+
+	next := NewGameScene(seed)
+	next.fixSeed(g.seedFixed)
+	return next
+}
+
+// fixSeed records whether the player picked the game's seed.
+func (g *GameScene) fixSeed(fixed bool) {
+	g.seedFixed = fixed
+	g.mode = modeFor(g.Seed(), fixed)
+}
+
+// titleSeed returns the seed to hand back to the title scene, so a seed the player picked sticks.
+func (g *GameScene) titleSeed() uint64 {
+	if g.seedFixed {
+		return g.Seed()
+	}
+	return 0
+}
+
+// Score returns the player's current score.
+func (g *GameScene) Score() int {
+	return g.score
+}
+
+// Seed returns the seed the game's random streams were split from. Starting a game with the
+// same seed and feeding it the same input frames plays it out exactly the same way.
+func (g *GameScene) Seed() uint64 {
+	return g.random.Seed()
+}
+
+// Replay returns the recording of the game so far.
+func (g *GameScene) Replay() *Replay {
+	return g.replay
+}
+
+// Mode returns the kind of game being played.
+func (g *GameScene) Mode() Mode {
+	return g.mode
+}
+
+// Level returns the level the player is on.
+func (g *GameScene) Level() int {
+	return g.currentLevel
+}
+
+// Phase returns whether a level is being played, the next one is about to start, or the game is
+// over.
+func (g *GameScene) Phase() Phase {
+	return g.phase
+}
+
+// isPressed reports whether action a is held down this tick.
+func (g *GameScene) isPressed(a Action) bool {
+	return g.input.Has(a)
+}
+
+// isJustPressed reports whether action a went down this tick.
+func (g *GameScene) isJustPressed(a Action) bool {
+	return g.input.Has(a) && !g.lastInput.Has(a)
+}
+
+// isJustReleased reports whether action a was let go this tick.
+func (g *GameScene) isJustReleased(a Action) bool {
+	return !g.input.Has(a) && g.lastInput.Has(a)
+}
+
+// stepLevelStart waits out the pause between levels. Firing skips the wait, but brings more
+// meteors to every level from then on.
+func (g *GameScene) stepLevelStart() {
+	g.nextLevelTimer.Update()
+	if g.nextLevelTimer.IsReady() {
+		g.startLevel()
+	} else if g.isJustPressed(ActionFire) {
+		g.extraMeteors += g.levels.SkipBonus
+		g.startLevel()
+	}
+}
+
+// startLevel starts the next level.
+func (g *GameScene) startLevel() {
+	for k, v := range g.projectiles {
+		delete(g.projectiles, k)
+		g.space.Remove(v.shape())
+	}
+	g.beginLevel()
+	g.phase = PhasePlaying
+}
+
+// beginLevel sets up level g.currentLevel from the level set: its meteor speed, aliens and
+// heartbeat, and its first wave.
+func (g *GameScene) beginLevel() {
+	last := g.level
+	g.level = g.levels.level(g.currentLevel, g.extraMeteors, g.tuning)
+	g.stats = levelStats{}
+
+	g.baseVelocity = g.startingVelocity()
+	g.velocityTimer = NewTimer(time.Duration(g.level.Speed.Every))
+	g.beatWaitTime = time.Duration(g.level.Beat.Start)
+
+	// Keep the alien timers going when the aliens are the same as last level, so they can turn up
+	// early in a level rather than always waiting out the first interval.
+	if !slices.Equal(last.Aliens, g.level.Aliens) {
+		g.alienTimers = newAlienTimers(g.level.Aliens)
+	}
+
+	// Every few levels, the boss turns up instead of the level's waves.
+	g.boss = nil
+	if g.isBossLevel(g.currentLevel) {
+		g.boss = NewBoss(g)
+		g.space.Add(g.boss.shapes()...)
+	}
+
+	g.startWave(0)
+}
+
+// isBossLevel reports whether level n is a boss level.
+func (g *GameScene) isBossLevel(n int) bool {
+	return n%g.tuning.Boss.Every == 0
+}
+
+// startWave starts the level's i'th wave.
+func (g *GameScene) startWave(i int) {
+	g.wave = i
+	g.waveSpawned = 0
+	g.meteorCount = 0
+	g.waveTimer = NewTimer(time.Duration(g.currentWave().Delay))
+	g.meteorSpawnTimer = NewTimer(time.Duration(g.currentWave().SpawnTime))
+}
+
+// currentWave returns the wave being played.
+func (g *GameScene) currentWave() Wave {
+	return g.level.Waves[g.wave]
+}
+
+// newAlienTimers creates a timer for each of schedules.
+func newAlienTimers(schedules []AlienSchedule) []*Timer {
+	timers := make([]*Timer, len(schedules))
+	for i, s := range schedules {
+		timers[i] = NewTimer(time.Duration(s.Every))
+	}
+	return timers
+}
+
+// LevelName returns the name of level n, if the level set gives it one.
+func (g *GameScene) LevelName(n int) string {
+	if g.isBossLevel(n) {
+		return "Mothership"
+	}
+	return g.levels.levelName(n)
+}
+
+// stepLevel updates all game elements while a level is being played.
+func (g *GameScene) stepLevel() {
+	g.stats.ticks++
+
 	// Update player.
 	g.player.Update()
+	g.watchPlayer()
 
 	// Update exhaust.
 	g.updateExhaust()
@@ -156,7 +423,7 @@ func (g *GameScene) Update(state *State) error {
 	g.isPlayerDying()
 
 	// Check to see if the player is dead.
-	g.isPlayerDead(state)
+	g.isPlayerDead()
 
 	// Spawn meteors.
 	g.spawnMeteors()
@@ -164,11 +431,15 @@ func (g *GameScene) Update(state *State) error {
 	// Spawn aliens.
 	g.spawnAliens()
 
-	// Update aliens.
+	// Move formations along, then update aliens.
+	g.updateSquadrons()
 	for _, a := range g.aliens {
 		a.Update()
 	}
 
+	// Update the boss, on boss levels.
+	g.updateBoss()
+
 	// Let aliens attack (and play alien sound).
 	g.letAliensAttack()
 
@@ -182,11 +453,20 @@ func (g *GameScene) Update(state *State) error {
 		m.Update()
 	}
 
-	// Update player lasers.
-	for _, l := range g.lasers {
-		l.Update()
+	// Update the player's lasers, beams, missiles and mines.
+	for _, k := range inOrder(g.projectiles) {
+		g.projectiles[k].Update()
 	}
 
+	// Update blasts, and let go of the ones that are over.
+	for _, b := range g.blasts {
+		b.Update()
+	}
+	g.blasts = slices.DeleteFunc(g.blasts, (*Blast).isOver)
+
+	// Update power-ups, and let go of the ones nobody picked up in time.
+	g.updatePowerUps()
+
 	// Speed up meteors over time.
 	g.speedUpMeteors()
 
@@ -205,6 +485,16 @@ func (g *GameScene) Update(state *State) error {
 	// Check for player laser collision with alien.
 	g.isAlienHitByPlayerLaser()
 
+	// Check for player laser collision with the boss, and the player flying into it.
+	g.isBossHitByPlayerLaser()
+	g.isPlayerCollidingWithBoss()
+
+	// Let aliens, their lasers and meteors hit each other.
+	g.applyCollisionRules()
+
+	// Check to see if the player picked up a power-up.
+	g.isPowerUpCollected()
+
 	// Get rid of offscreen meteors & aliens.
 	g.cleanUpMeteorsAndAliens()
 
@@ -212,15 +502,13 @@ func (g *GameScene) Update(state *State) error {
 	g.beatSound()
 
 	// Is the level complete?
-	g.isLevelComplete(state)
+	g.isLevelComplete()
 
 	// Clean up offscreen aliens.
 	g.removeOffscreenAliens()
 
-	// Clean up offscreen lasers.
+	// Clean up offscreen lasers, and spent projectiles.
 	g.removeOffscreenLasers()
-
-	return nil
 }
 
 // Draw draws all game scene elements to the screen. It's called once per frame.
@@ -249,11 +537,24 @@ func (g *GameScene) Draw(screen *ebiten.Image) {
 		m.Draw(screen)
 	}
 
-	// Draw lasers.
-	for _, l := range g.lasers {
-		l.Draw(screen)
+	// Draw power-ups.
+	for _, p := range g.powerUps {
+		p.Draw(screen)
+	}
+
+	// Draw blasts.
+	for _, b := range g.blasts {
+		b.Draw(screen)
 	}
 
+	// Draw lasers, beams, missiles and mines.
+	for _, s := range g.projectiles {
+		s.Draw(screen)
+	}
+
+	// Draw anything the weapon in use shows.
+	g.player.currentWeapon().Draw(screen, g.player)
+
 	// Draw life indicators.
 	if len(g.player.lifeIndicators) > 0 {
 		for _, x := range g.player.lifeIndicators {
@@ -273,6 +574,9 @@ func (g *GameScene) Draw(screen *ebiten.Image) {
 		g.player.hyperspaceIndicator.Draw(screen)
 	}
 
+	// Draw the power-ups that are working.
+	g.player.effects.Draw(screen)
+
 	// Draw aliens.
 	for _, a := range g.aliens {
 		a.Draw(screen)
@@ -283,6 +587,12 @@ func (g *GameScene) Draw(screen *ebiten.Image) {
 		al.Draw(screen)
 	}
 
+	// Draw the boss, and its health.
+	if g.boss != nil {
+		g.boss.Draw(screen)
+		g.boss.DrawHealth(screen)
+	}
+
 	// Update and draw score.
 	textToDraw := fmt.Sprintf("%06d", g.score)
 	op := &text.DrawOptions{
@@ -297,12 +607,8 @@ func (g *GameScene) Draw(screen *ebiten.Image) {
 		Size:   24,
 	}, op)
 
-	// Update and draw high score.
-	if g.score >= highScore {
-		highScore = g.score
-	}
-
-	textToDraw = fmt.Sprintf("HIGH SCORE %06d", highScore)
+	// Draw high score.
+	textToDraw = fmt.Sprintf("HIGH SCORE %06d", max(highScores.Best(), g.score))
 	op = &text.DrawOptions{
 		LayoutOptions: text.LayoutOptions{
 			PrimaryAlign: text.AlignCenter,
@@ -315,6 +621,20 @@ func (g *GameScene) Draw(screen *ebiten.Image) {
 		Size:   16,
 	}, op)
 
+	// Draw the weapon in use.
+	textToDraw = weaponNames[g.player.currentWeapon().Kind()]
+	op = &text.DrawOptions{
+		LayoutOptions: text.LayoutOptions{
+			PrimaryAlign: text.AlignStart,
+		},
+	}
+	op.ColorScale.ScaleWithColor(color.White)
+	op.GeoM.Translate(20, ScreenHeight-40)
+	text.Draw(screen, textToDraw, &text.GoTextFace{
+		Source: assets.LevelFont,
+		Size:   16,
+	}, op)
+
 	// Update and draw current level.
 	textToDraw = fmt.Sprintf("LEVEL %d", g.currentLevel)
 	op = &text.DrawOptions{
@@ -339,12 +659,14 @@ func (g *GameScene) isPlayerCollidingWithAlien() {
 	for _, a := range g.aliens {
 		if a.alienObj.IsIntersecting(g.player.playerObj) {
 			if !a.game.player.isShielded {
-				if !a.game.explosionPlayer.IsPlaying(){
-					_ = a.game.explosionPlayer.Rewind()
-					a.game.explosionPlayer.Play()
-				}
+				a.game.audio.Play(SoundExplosion)
 				a.game.player.isDying = true
 			}
+			// Kamikazes go up with whatever they ram, shield or no shield. There are no points for it.
+			if a.behaviour == BehaviourKamikaze && a.sprite != g.explosionSprite {
+				a.sprite = g.explosionSprite
+				g.audio.Play(SoundExplosion)
+			}
 		}
 	}
 }
@@ -353,10 +675,7 @@ func (g *GameScene) isPlayerHitByAlienLaser() {
 	for _, l := range g.alienLasers {
 		if l.laserObj.IsIntersecting(g.player.playerObj) {
 			if !g.player.isShielded {
-				if !g.explosionPlayer.IsPlaying(){
-					_ = g.explosionPlayer.Rewind()
-					g.explosionPlayer.Play()
-				}
+				g.audio.Play(SoundExplosion)
 				g.player.isDying = true
 			}
 		}
@@ -364,29 +683,184 @@ func (g *GameScene) isPlayerHitByAlienLaser() {
 }
 
 func (g *GameScene) isAlienHitByPlayerLaser() {
-	for _, a := range g.aliens {
-		for _, l := range g.lasers {
-			if a.alienObj.IsIntersecting(l.laserObj) {
-				laserData := l.laserObj.Data().(*ObjectData)
-				delete(g.alienLasers, laserData.index)
-				g.space.Remove(l.laserObj)
-				a.sprite = g.explosionSprite
-				g.score = g.score + 50
-				if !g.explosionPlayer.IsPlaying() {
-					_ = g.explosionPlayer.Rewind()
-					g.explosionPlayer.Play()
-				}
+	for _, k := range inOrder(g.aliens) {
+		a := g.aliens[k]
+		if a.sprite == g.explosionSprite {
+			continue
+		}
+		for _, i := range inOrder(g.projectiles) {
+			if g.projectiles[i].strike(a.alienObj) {
+				g.killAlien(a, creditPlayer)
+				break
+			}
+		}
+	}
+}
+
+// killAlien blows up alien a. If it's down to the player, they score for it, and it maybe leaves
+// a power-up behind.
+func (g *GameScene) killAlien(a *Alien, by credit) {
+	a.sprite = g.explosionSprite
+	g.audio.Play(SoundExplosion)
+	if by != creditPlayer {
+		return
+	}
+
+	g.addScore(50)
+	g.dropPowerUp(a.centre(), g.tuning.PowerUps.AlienDropChance)
+}
+
+// updateBoss moves the boss along and lets it attack, on boss levels. Once it's blown up, its
+// collision objects are taken out of the space.
+func (g *GameScene) updateBoss() {
+	if g.boss == nil || g.boss.isDestroyed() {
+		return
+	}
+
+	g.boss.Update()
+	if g.boss.isDestroyed() {
+		g.space.Remove(g.boss.shapes()...)
+	}
+}
+
+// isBossHitByPlayerLaser checks the player's shots against the boss. Its turrets and core are hurt
+// by them, and its hull stops them.
+func (g *GameScene) isBossHitByPlayerLaser() {
+	if g.boss == nil || g.boss.isDying() {
+		return
+	}
+
+	for _, i := range inOrder(g.projectiles) {
+		s := g.projectiles[i]
+		for _, p := range g.boss.parts() {
+			if !p.isDestroyed() && s.strike(p.partObj) {
+				g.hitBoss(p, creditPlayer)
 			}
 		}
+		s.strike(g.boss.hullObj)
+	}
+}
+
+// isPlayerCollidingWithBoss checks whether the player's flown into the boss.
+func (g *GameScene) isPlayerCollidingWithBoss() {
+	if g.boss == nil || g.boss.isDying() {
+		return
+	}
+
+	for _, s := range g.boss.shapes() {
+		if s.IsIntersecting(g.player.playerObj) {
+			if !g.player.isShielded {
+				g.audio.Play(SoundExplosion)
+				g.player.isDying = true
+			}
+			return
+		}
+	}
+}
+
+// hitBoss hits part p of the boss, unless it's armoured. A part that's shot away blows up; if it's
+// the core, the whole boss goes with it. Turrets only score if the player knocked them out.
+func (g *GameScene) hitBoss(p *bossPart, by credit) {
+	if p.isDestroyed() || g.boss.isArmoured(p) {
+		return
+	}
+
+	p.health--
+	p.hitFlash = bossHitFlash
+	if !p.isDestroyed() {
+		return
+	}
+
+	g.space.Remove(p.partObj)
+	g.blasts = append(g.blasts, NewBlast(g.boss.centreOf(p), 50))
+	g.audio.Play(SoundExplosion)
+	if p != g.boss.core && by == creditPlayer {
+		g.addScore(g.tuning.Boss.TurretPoints)
+	}
+}
+
+// bossTargetCentre returns the middle of the boss part shape is the collision object of, as long
+// as it's still there and can be hurt. The hull can't be locked on to.
+func (g *GameScene) bossTargetCentre(shape resolv.IShape) (Vector, bool) {
+	if g.boss == nil || g.boss.isDying() {
+		return Vector{}, false
+	}
+	for _, p := range g.boss.parts() {
+		if p.partObj == shape && !p.isDestroyed() && !g.boss.isArmoured(p) {
+			return g.boss.centreOf(p), true
+		}
+	}
+	return Vector{}, false
+}
+
+// addScore adds points to the score, multiplied if the multiplier power-up is working.
+func (g *GameScene) addScore(points int) {
+	if g.player.effects.has(PowerUpMultiplier) {
+		points *= g.tuning.PowerUps.Multiplier
+	}
+	g.score += points
+}
+
+// dropPowerUp drops a power-up of a random kind at pos, with a chance in 100.
+func (g *GameScene) dropPowerUp(pos Vector, chance int) {
+	if g.random.powerUps.IntN(100) >= chance {
+		return
+	}
+
+	kind := powerUpKinds[g.random.powerUps.IntN(len(powerUpKinds))]
+	g.powerUpCount++
+	p := NewPowerUp(kind, pos, g, g.powerUpCount, g.random.powerUps)
+	g.powerUps[g.powerUpCount] = p
+	g.space.Add(p.powerUpObj)
+}
+
+// updatePowerUps drifts the power-ups along, and gets rid of the ones that have run out of time.
+func (g *GameScene) updatePowerUps() {
+	for _, k := range inOrder(g.powerUps) {
+		p := g.powerUps[k]
+		p.Update()
+		if p.isExpired() {
+			g.space.Remove(p.powerUpObj)
+			delete(g.powerUps, k)
+		}
+	}
+}
+
+// isPowerUpCollected checks whether the player has flown into a power-up, and if so, uses it.
+func (g *GameScene) isPowerUpCollected() {
+	if g.player.isDying {
+		return
+	}
+	for _, k := range inOrder(g.powerUps) {
+		p := g.powerUps[k]
+		if p.powerUpObj.IsIntersecting(g.player.playerObj) {
+			g.space.Remove(p.powerUpObj)
+			delete(g.powerUps, k)
+			g.collectPowerUp(p.kind)
+		}
+	}
+}
+
+// collectPowerUp does what a power-up of kind does.
+func (g *GameScene) collectPowerUp(kind PowerUpKind) {
+	g.audio.Play(SoundShieldsUp)
+	switch kind {
+	case PowerUpShield:
+		g.player.addShield()
+	case PowerUpHyperspace:
+		g.player.hyperSpaceTimer = nil
+	case PowerUpExtraLife:
+		g.player.addLife()
+	default:
+		if kind.isTimed() {
+			g.player.effects.start(kind, time.Duration(g.tuning.PowerUps.EffectTime))
+		}
 	}
 }
 
 func (g *GameScene) letAliensAttack() {
 	if len(g.aliens) > 0 {
-		if !g.alienSoundPlayer.IsPlaying() {
-			_ = g.alienSoundPlayer.Rewind()
-			g.alienSoundPlayer.Play()
-		}
+		g.audio.Play(SoundAlien)
 
 		// Update the alien attack timer.
 		g.alienAttackTimer.Update()
@@ -395,7 +869,14 @@ func (g *GameScene) letAliensAttack() {
 		if g.alienAttackTimer.IsReady() {
 			g.alienAttackTimer.Reset()
 
-			for _, a := range g.aliens {
+			for _, k := range inOrder(g.aliens) {
+				a := g.aliens[k]
+
+				// Aliens in formation hold their fire until they break off to dive.
+				if a.squadron != nil && a.flight != flightDiving {
+					continue
+				}
+
 				bounds := a.sprite.Bounds()
 				halfW := float64(bounds.Dx()) / 2
 				halfH := float64(bounds.Dy()) / 2
@@ -405,11 +886,10 @@ func (g *GameScene) letAliensAttack() {
 				// Is the alien intelligent?
 				if !a.isIntelligent {
 					// Fire in a random direction.
-					degreesRadian = rand.Float64() * (math.Pi * 2)
+					degreesRadian = g.random.aliens.Float64() * (math.Pi * 2)
 				} else {
-					// Fire with some accuracy.
-					degreesRadian = math.Atan2(g.player.position.Y-a.position.Y, g.player.position.X-a.position.X)
-					degreesRadian = degreesRadian - math.Pi*-0.5
+					// Lead the ship, as well as the alien can.
+					degreesRadian = g.aimAt(a.centre())
 				}
 
 				r := degreesRadian
@@ -422,23 +902,18 @@ func (g *GameScene) letAliensAttack() {
 					Y: a.position.Y + halfH + math.Cos(r) - offsetY,
 				}
 
-				laser := NewAlienLaser(spawnPos, r)
-				g.alienLaserCount++
-				g.alienLasers[g.alienLaserCount] = laser
-				if !g.alienLaserPlayer.IsPlaying() {
-					_ = g.alienLaserPlayer.Rewind()
-					g.alienLaserPlayer.Play()
-				}
+				g.addAlienLaser(spawnPos, r)
+				g.audio.Play(SoundAlienLaser)
 			}
 		}
 	}
 }
 
 func (g *GameScene) removeOffscreenLasers() {
-	for i, l := range g.lasers {
-		if l.position.X > ScreenWidth+200 || l.position.Y > ScreenHeight+200 || l.position.X < -200 || l.position.Y < -200 {
-			g.space.Remove(l.laserObj)
-			delete(g.lasers, i)
+	for i, s := range g.projectiles {
+		if s.isSpent() {
+			g.space.Remove(s.shape())
+			delete(g.projectiles, i)
 		}
 	}
 
@@ -450,17 +925,33 @@ func (g *GameScene) removeOffscreenLasers() {
 	}
 }
 
+// spawnAliens sends aliens according to the level's schedules.
 func (g *GameScene) spawnAliens() {
-	g.alienSpawnTimer.Update()
-	if len(g.aliens) == 0 {
-		if g.alienSpawnTimer.IsReady() {
-			g.alienSpawnTimer.Reset()
-			rnd := rand.IntN(100-1) + 1
-			if rnd > 50 {
-				a := NewAlien(baseAlienVelocity, g)
-				g.space.Add(a.alienObj)
-				g.alienCount++
-				g.aliens[g.alienCount] = a
+	// The boss brings its own.
+	if g.boss != nil {
+		return
+	}
+
+	for i, s := range g.level.Aliens {
+		t := g.alienTimers[i]
+		t.Update()
+
+		// Single aliens count against every schedule of single aliens, and formations against
+		// the schedules for the same formation.
+		about := g.loneAliens()
+		if s.Formation != "" {
+			about = g.squadronsFlying(s.Formation)
+		}
+
+		if about < s.Max && t.IsReady() {
+			t.Reset()
+			rnd := g.random.aliens.IntN(100-1) + 1
+			if rnd > 100-s.Chance {
+				if f, ok := findFormation(s.Formation); ok {
+					g.launchSquadron(f)
+				} else {
+					g.addAlien(NewAlien(g.tuning.Aliens.BaseVelocity, s.Kind, s.Behaviour, g))
+				}
 			}
 		}
 	}
@@ -481,54 +972,78 @@ func (g *GameScene) updateShield() {
 	}
 }
 
-// isLevelComplete checks to see if the level is complete (all meteors destroyed).
-func (g *GameScene) isLevelComplete(state *State) {
-	if g.meteorCount >= g.meteorsForLevel && len(g.meteors) == 0 {
-		// Level finished, so reset meteor velocity.
-		g.baseVelocity = baseMeteorVelocity
-		// Increase current level by one.
-		g.currentLevel++
+// isLevelComplete checks to see if the wave is cleared (all its meteors destroyed), and moves on
+// to the next wave, or the next level if it was the last.
+func (g *GameScene) isLevelComplete() {
+	// A boss level's done once the boss is destroyed, and the meteors it summoned are cleared.
+	if g.boss != nil {
+		if g.boss.isDestroyed() && len(g.meteors) == 0 {
+			g.finishLevel()
+		}
+		return
+	}
 
-		// If we've done 5 levels, add a life.
-		if g.currentLevel%5 == 0 {
-			if g.player.livesRemaining < 6 {
-				g.player.livesRemaining++
-				x := float64(20 + len(g.player.lifeIndicators)*50.0)
-				y := 20.0
-				g.player.lifeIndicators = append(g.player.lifeIndicators, NewLifeIndicator(Vector{X: x, Y: y}))
-			}
+	if g.waveSpawned >= g.currentWave().size() && len(g.meteors) == 0 {
+		if g.wave+1 < len(g.level.Waves) {
+			g.startWave(g.wave + 1)
+			return
 		}
+		g.finishLevel()
+	}
+}
 
-		// Set the beat time to slowest.
-		g.beatWaitTime = baseBeatWaitTime
+// finishLevel hands out the level's bonuses, and moves on to the next level.
+func (g *GameScene) finishLevel() {
+	// Did the player earn the level's bonus?
+	g.bonuses = nil
+	if b := g.level.Bonus; b != nil && b.earned(g.stats) {
+		g.score += b.Points
+		g.bonuses = append(g.bonuses, b)
+	}
 
-		// Switch scenes.
-		state.SceneManager.GoToScene(&LevelStartsScene{
-			game:           g,
-			nextLevelTimer: NewTimer(time.Second * 2),
-			stars:          GenerateStars(numberOfStars),
-		})
+	// Beating the boss earns a bonus of its own.
+	if g.boss != nil {
+		b := &Bonus{Kind: BonusBoss, Points: g.tuning.Boss.VictoryBonus}
+		g.score += b.Points
+		g.bonuses = append(g.bonuses, b)
+		g.boss = nil
+	}
+
+	// Increase current level by one.
+	g.currentLevel++
+
+	// Every few levels, add a life.
+	if g.currentLevel%g.tuning.Levels.ExtraLifeEvery == 0 {
+		g.player.addLife()
 	}
+
+	// Wait for the next level.
+	g.nextLevelTimer.Reset()
+	g.phase = PhaseLevelStarting
 }
 
 func (g *GameScene) beatSound() {
+	// The boss has its own music instead.
+	if g.boss != nil && !g.boss.isDestroyed() {
+		g.audio.Play(SoundBoss)
+		return
+	}
+
 	g.beatTimer.Update()
 	if g.beatTimer.IsReady() {
 		if g.playBeatOne {
-			_ = g.beatOnePlayer.Rewind()
-			g.beatOnePlayer.Play()
+			g.audio.Restart(SoundBeatOne)
 			g.beatTimer.Reset()
 		} else {
-			_ = g.beatTwoPlayer.Rewind()
-			g.beatTwoPlayer.Play()
+			g.audio.Restart(SoundBeatTwo)
 			g.beatTimer.Reset()
 		}
 		g.playBeatOne = !g.playBeatOne
 
 		// Speed up the timer.
-		if g.beatWaitTime > 400 {
-			g.beatWaitTime = g.beatWaitTime - 25
-			g.beatTimer = NewTimer(time.Millisecond * time.Duration(g.beatWaitTime))
+		if g.beatWaitTime > time.Duration(g.level.Beat.Min) {
+			g.beatWaitTime = g.beatWaitTime - time.Duration(g.level.Beat.SpeedUp)
+			g.beatTimer = NewTimer(g.beatWaitTime)
 		}
 	}
 }
@@ -540,46 +1055,301 @@ func (g *GameScene) updateExhaust() {
 }
 
 func (g *GameScene) isMeteorHitByPlayerLaser() {
-	for _, m := range g.meteors {
-		for _, l := range g.lasers {
-			if m.meteorObj.IsIntersecting(l.laserObj) {
-				if m.meteorObj.Tags().Has(TagSmall) {
-					// Small meteor hit.
-					m.sprite = g.explosionSmallSprite
-					g.score++
-
-					if !g.explosionPlayer.IsPlaying() {
-						_ = g.explosionPlayer.Rewind()
-						g.explosionPlayer.Play()
-					}
-				} else {
-					// Large meteor hit.
-					oldPos := m.position
-
-					m.sprite = g.explosionSprite
-
-					g.score++
-
-					if !g.explosionPlayer.IsPlaying() {
-						_ = g.explosionPlayer.Rewind()
-						g.explosionPlayer.Play()
-					}
-
-					numToSpawn := rand.IntN(numberOfSmallMeteorsFromLargeMeteor)
-					for i := 0; i < numToSpawn; i++ {
-						meteor := NewSmallMeteor(baseMeteorVelocity, g, len(m.game.meteors)-1)
-						meteor.position = Vector{oldPos.X + float64(rand.IntN(100-50)+50), oldPos.Y + float64(rand.IntN(100-50)+50)}
-						meteor.meteorObj.SetPosition(meteor.position.X, meteor.position.Y)
-						g.space.Add(meteor.meteorObj)
-						g.meteorCount++
-						g.meteors[m.game.meteorCount] = meteor
-					}
-				}
+	for _, k := range inOrder(g.meteors) {
+		m := g.meteors[k]
+		if m.isExploding() {
+			continue
+		}
+		for _, i := range inOrder(g.projectiles) {
+			s := g.projectiles[i]
+			if s.strike(m.meteorObj) {
+				g.hitMeteor(m, s.point(), creditPlayer)
+				break
+			}
+		}
+	}
+}
+
+// hitMeteor hits m with a shot, or a blast, from impact. Iron meteors take a few hits before they
+// break in two. Rock ones break in two straight away, ice ones shatter, and explosive ones blow up.
+// Breaking it only scores, and maybe drops a power-up, if it's down to the player.
+func (g *GameScene) hitMeteor(m *Meteor, impact Vector, by credit) {
+	m.damage++
+	if m.damage < m.hitsToBreak(g.tuning) {
+		m.hitFlash = meteorHitFlash
+		return
+	}
+
+	g.audio.Play(SoundExplosion)
+
+	centre := m.centre()
+	if by == creditPlayer {
+		g.addScore(g.meteorScore(m))
+		g.dropPowerUp(centre, g.tuning.PowerUps.MeteorDropChance)
+	}
+	switch m.material {
+	case MaterialIce:
+		g.shatterMeteor(m)
+	case MaterialExplosive:
+		// Nothing's left to split.
+	default:
+		g.splitMeteor(m, impact)
+	}
+	m.sprite = meteorTiers[m.size].explosion
+
+	if m.material == MaterialExplosive {
+		g.detonate(centre, m.size, by)
+	}
+}
+
+// meteorScore returns the points for breaking m: its size's points, times its material's.
+func (g *GameScene) meteorScore(m *Meteor) int {
+	var points int
+	switch m.size {
+	case MeteorMedium:
+		points = g.tuning.Meteors.ScoreMedium
+	case MeteorSmall, meteorShard:
+		points = g.tuning.Meteors.ScoreSmall
+	default:
+		points = g.tuning.Meteors.ScoreLarge
+	}
+
+	switch m.material {
+	case MaterialIron:
+		return points * g.tuning.Materials.IronScore
+	case MaterialIce:
+		return points * g.tuning.Materials.IceScore
+	case MaterialExplosive:
+		return points * g.tuning.Materials.ExplosiveScore
+	default:
+		return points * g.tuning.Materials.RockScore
+	}
+}
+
+// addMeteor puts m into play.
+func (g *GameScene) addMeteor(m *Meteor) {
+	g.space.Add(m.meteorObj)
+	g.meteorCount++
+	g.meteors[g.meteorCount] = m
+	m.meteorObj.SetData(&ObjectData{index: g.meteorCount})
+}
+
+// splitMeteor breaks m into pieces of the next size down, if there is one. The pieces keep the
+// meteor's momentum, and fan out away from where the shot hit it.
+func (g *GameScene) splitMeteor(m *Meteor, impact Vector) {
+	size := meteorTiers[m.size].breaksInto
+	if size == "" {
+		return
+	}
+
+	centre := m.centre()
+	away := Vector{X: centre.X - impact.X, Y: centre.Y - impact.Y}
+	heading := math.Atan2(away.Y, away.X)
+	if away.X == 0 && away.Y == 0 {
+		heading = math.Atan2(m.movement.Y, m.movement.X)
+	}
+
+	tuning := g.tuning.Meteors
+	spread := tuning.SplitSpread * math.Pi / 180
+	for i := 0; i < tuning.PiecesPerSplit; i++ {
+		// Spread the pieces evenly across the fan, with a little wobble so it's not too neat.
+		angle := heading
+		if tuning.PiecesPerSplit > 1 {
+			angle += spread * (float64(i)/float64(tuning.PiecesPerSplit-1) - 0.5)
+		}
+		angle += (g.random.meteors.Float64() - 0.5) * spread / 4
+		direction := Vector{X: math.Cos(angle), Y: math.Sin(angle)}
+
+		piece := newMeteor(size, 0, EdgeAny, g, len(g.meteors)-1, g.random.meteors)
+
+		// Start the piece in the middle of its parent, nudged along its way out.
+		bounds := piece.sprite.Bounds()
+		offset := float64(bounds.Dx()) / 2
+		pos := Vector{
+			X: centre.X + direction.X*offset - float64(bounds.Dx())/2,
+			Y: centre.Y + direction.Y*offset - float64(bounds.Dy())/2,
+		}
+		velocity := Vector{
+			X: m.movement.X + direction.X*tuning.SplitKick,
+			Y: m.movement.Y + direction.Y*tuning.SplitKick,
+		}
+		piece.place(pos, &velocity, 1)
+		piece.material = m.material
+		g.addMeteor(piece)
+	}
+}
+
+// shatterMeteor breaks an ice meteor into shards, which fly off fast in every direction. Shards
+// don't shatter again.
+func (g *GameScene) shatterMeteor(m *Meteor) {
+	if m.size == meteorShard {
+		return
+	}
+
+	centre := m.centre()
+	shards := g.tuning.Materials.IceShards
+	for i := 0; i < shards; i++ {
+		angle := 2*math.Pi*(float64(i)+g.random.meteors.Float64()/2)/float64(shards)
+		direction := Vector{X: math.Cos(angle), Y: math.Sin(angle)}
+
+		shard := newMeteor(meteorShard, 0, EdgeAny, g, len(g.meteors)-1, g.random.meteors)
+		bounds := shard.sprite.Bounds()
+		pos := Vector{
+			X: centre.X - float64(bounds.Dx())/2,
+			Y: centre.Y - float64(bounds.Dy())/2,
+		}
+		velocity := Vector{
+			X: m.movement.X + direction.X*g.tuning.Materials.IceShardSpeed,
+			Y: m.movement.Y + direction.Y*g.tuning.Materials.IceShardSpeed,
+		}
+		shard.place(pos, &velocity, 1)
+		shard.material = MaterialIce
+		g.addMeteor(shard)
+	}
+}
+
+// detonate blows up an explosive meteor of the given size at centre. Every meteor and alien the
+// blast reaches is hit, so explosive meteors caught in it blow up in turn. The blast is down to
+// whoever set the meteor off.
+func (g *GameScene) detonate(centre Vector, size MeteorSize, by credit) {
+	g.explode(centre, g.tuning.Materials.ExplosiveRadius*meteorTiers[size].radius/meteorTiers[MeteorLarge].radius, by)
+}
+
+// explode sets off a blast at centre, reaching radius. Every meteor and alien it reaches is hit,
+// and what it breaks is put down to by.
+func (g *GameScene) explode(centre Vector, radius float64, by credit) {
+	g.blasts = append(g.blasts, NewBlast(centre, radius))
+
+	for _, k := range inOrder(g.meteors) {
+		m := g.meteors[k]
+		if m.isExploding() {
+			continue
+		}
+		if centre.Distance(m.centre()) <= radius+meteorTiers[m.size].radius {
+			g.hitMeteor(m, centre, by)
+		}
+	}
+
+	for _, k := range inOrder(g.aliens) {
+		a := g.aliens[k]
+		if a.sprite == g.explosionSprite {
+			continue
+		}
+		if centre.Distance(a.centre()) <= radius+float64(a.sprite.Bounds().Dx())/2 {
+			g.killAlien(a, by)
+		}
+	}
+
+	if g.boss != nil && !g.boss.isDying() {
+		for _, p := range g.boss.parts() {
+			if !p.isDestroyed() && centre.Distance(g.boss.centreOf(p)) <= radius+p.partObj.Radius() {
+				g.hitBoss(p, by)
 			}
 		}
 	}
 }
 
+// lockOn returns the alien, large meteor or boss part a missile at pos, heading at rotation,
+// should lock on to: the nearest that's in range and in front of it, within the lock cone. It
+// returns nil if there's nothing to lock on to.
+func (g *GameScene) lockOn(pos Vector, rotation float64) resolv.IShape {
+	w := g.tuning.Weapons
+	cone := w.MissileLockCone * math.Pi / 180 / 2
+
+	var target resolv.IShape
+	best := w.MissileLockRange
+	g.space.FilterShapes().ByTags(TagAlien | TagLarge | TagBoss).NotByTags(TagLaser).ForEach(func(shape resolv.IShape) bool {
+		centre, ok := g.targetCentre(shape)
+		if !ok || !pos.InCone(centre, rotation, cone) {
+			return true
+		}
+		if d := pos.Distance(centre); d < best {
+			best, target = d, shape
+		}
+		return true
+	})
+	return target
+}
+
+// targetCentre returns the middle of the meteor, alien or boss part shape is the collision object
+// of, as long as it's still about and hasn't been hit.
+func (g *GameScene) targetCentre(shape resolv.IShape) (Vector, bool) {
+	if shape.Tags().Has(TagBoss) {
+		return g.bossTargetCentre(shape)
+	}
+
+	if m, ok := g.meteorOf(shape); ok {
+		return m.centre(), true
+	}
+	if a, ok := g.alienOf(shape); ok {
+		return a.centre(), true
+	}
+	return Vector{}, false
+}
+
+// meteorOf returns the meteor shape is the collision object of, as long as it's still about and
+// hasn't been broken.
+func (g *GameScene) meteorOf(shape resolv.IShape) (*Meteor, bool) {
+	data, ok := shape.Data().(*ObjectData)
+	if !ok || !shape.Tags().Has(TagMeteor) {
+		return nil, false
+	}
+	if m, ok := g.meteors[data.index]; ok && m.meteorObj == shape && !m.isExploding() {
+		return m, true
+	}
+	return nil, false
+}
+
+// alienOf returns the alien shape is the collision object of, as long as it's still about and
+// hasn't been shot down.
+func (g *GameScene) alienOf(shape resolv.IShape) (*Alien, bool) {
+	data, ok := shape.Data().(*ObjectData)
+	if !ok || !shape.Tags().Has(TagAlien) || shape.Tags().Has(TagLaser) {
+		return nil, false
+	}
+	if a, ok := g.aliens[data.index]; ok && a.alienObj == shape && a.sprite != g.explosionSprite {
+		return a, true
+	}
+	return nil, false
+}
+
+// alienLaserOf returns the key in alienLasers of the alien laser shape is the collision object
+// of, as long as it's still flying.
+func (g *GameScene) alienLaserOf(shape resolv.IShape) (int, bool) {
+	data, ok := shape.Data().(*ObjectData)
+	if !ok || !shape.Tags().Has(TagLaser) {
+		return 0, false
+	}
+	if l, ok := g.alienLasers[data.index]; ok && l.laserObj == shape {
+		return data.index, true
+	}
+	return 0, false
+}
+
+// addAlien puts a into play.
+func (g *GameScene) addAlien(a *Alien) {
+	g.space.Add(a.alienObj)
+	g.alienCount++
+	g.aliens[g.alienCount] = a
+	a.alienObj.SetData(&ObjectData{index: g.alienCount})
+}
+
+// addAlienLaser fires an alien laser from pos, heading at rotation.
+func (g *GameScene) addAlienLaser(pos Vector, rotation float64) {
+	g.alienLaserCount++
+	l := NewAlienLaser(pos, rotation, g.tuning.Aliens.LaserSpeed)
+	l.laserObj.SetData(&ObjectData{index: g.alienLaserCount})
+	g.alienLasers[g.alienLaserCount] = l
+	g.space.Add(l.laserObj)
+}
+
+// addProjectile puts something the player's fired into play.
+func (g *GameScene) addProjectile(s Projectile) {
+	g.projectileCount++
+	g.projectiles[g.projectileCount] = s
+	g.space.Add(s.shape())
+}
+
 func (g *GameScene) isPlayerDying() {
 	if g.player.isDying {
 		g.player.dyingTimer.Update()
@@ -598,25 +1368,12 @@ func (g *GameScene) isPlayerDying() {
 	}
 }
 
-func (g *GameScene) isPlayerDead(state *State) {
+func (g *GameScene) isPlayerDead() {
 	if g.playerIsDead {
 		g.player.livesRemaining--
+		g.stats.deaths++
 		if g.player.livesRemaining == 0 {
-
-			// New High Score?
-			if g.score > originalHighScore {
-				err := updateHighScore(g.score)
-				if err != nil {
-					log.Println(err)
-				}
-			}
-
-			state.SceneManager.GoToScene(&GameOverScene{
-				game:        g,
-				meteors:     make(map[int]*Meteor),
-				meteorCount: 5,
-				stars:       GenerateStars(numberOfStars),
-			})
+			g.phase = PhaseGameOver
 		} else {
 			score := g.score
 			livesRemaining := g.player.livesRemaining
@@ -624,6 +1381,7 @@ func (g *GameScene) isPlayerDead(state *State) {
 			stars := g.stars
 			shieldsRemaining := g.player.shieldsRemaining
 			shieldIndicatorSlice := g.player.shieldIndicators
+			weapon := g.player.weapon
 
 			g.Reset()
 
@@ -633,30 +1391,53 @@ func (g *GameScene) isPlayerDead(state *State) {
 			g.stars = stars
 			g.player.shieldsRemaining = shieldsRemaining
 			g.player.shieldIndicators = shieldIndicatorSlice
+			g.player.weapon = weapon
 		}
 	}
 }
 
-// spawnMeteors creates meteors, up to the maximum for a level.
+// spawnMeteors creates the wave's meteors, in order, once its delay is up. Meteors broken off
+// bigger ones count towards the wave too.
 func (g *GameScene) spawnMeteors() {
+	// The boss summons its own.
+	if g.boss != nil {
+		return
+	}
+
+	g.waveTimer.Update()
+	if !g.waveTimer.IsReady() {
+		return
+	}
+
 	g.meteorSpawnTimer.Update()
 	if g.meteorSpawnTimer.IsReady() {
 		g.meteorSpawnTimer.Reset()
-		if len(g.meteors) < g.meteorsForLevel && g.meteorCount < g.meteorsForLevel {
-			m := NewMeteor(g.baseVelocity, g, len(g.meteors)-1)
-			g.space.Add(m.meteorObj)
-			g.meteorCount++
-			g.meteors[g.meteorCount] = m
+		w := g.currentWave()
+		if g.waveSpawned < w.size() {
+			group := w.group(g.waveSpawned)
+			m := newMeteor(group.Size, g.baseVelocity, group.Edge, g, len(g.meteors)-1, g.random.meteors)
+			m.material = group.Material
+			if m.material == "" {
+				m.material = g.level.material(g.random.meteors)
+			}
+			if group.At != nil {
+				m.place(*group.At, group.Velocity, g.difficulty.meteorSpeed())
+			}
+			g.waveSpawned++
+			g.addMeteor(m)
 		}
 	}
 }
 
-// speedUpMeteors makes meteors move faster over time.
+// speedUpMeteors makes meteors move faster over time, up to the level's limit.
 func (g *GameScene) speedUpMeteors() {
 	g.velocityTimer.Update()
 	if g.velocityTimer.IsReady() {
 		g.velocityTimer.Reset()
-		g.baseVelocity += meteorSpeedUpAmount
+		g.baseVelocity += g.level.Speed.SpeedUp
+		if g.level.Speed.Max > 0 {
+			g.baseVelocity = min(g.baseVelocity, g.level.Speed.Max*g.difficulty.meteorSpeed())
+		}
 	}
 }
 
@@ -666,10 +1447,7 @@ func (g *GameScene) isPlayerCollidingWithMeteor() {
 			if !g.player.isShielded {
 				m.game.player.isDying = true
 
-				if !g.explosionPlayer.IsPlaying() {
-					_ = g.explosionPlayer.Rewind()
-					g.explosionPlayer.Play()
-				}
+				g.audio.Play(SoundExplosion)
 				break
 			} else {
 				// Bounce the meteor.
@@ -699,7 +1477,7 @@ func (g *GameScene) cleanUpMeteorsAndAliens() {
 	g.cleanUpTimer.Update()
 	if g.cleanUpTimer.IsReady() {
 		for i, m := range g.meteors {
-			if m.sprite == g.explosionSprite || m.sprite == g.explosionSmallSprite {
+			if m.isExploding() {
 				delete(g.meteors, i)
 				g.space.Remove(m.meteorObj)
 			}
@@ -719,21 +1497,29 @@ func (g *GameScene) Reset() {
 	g.player = NewPlayer(g)
 	g.meteors = make(map[int]*Meteor)
 	g.meteorCount = 0
-	g.lasers = make(map[int]*Laser)
-	g.laserCount = 0
+	g.waveSpawned = 0
+	g.projectiles = make(map[int]Projectile)
+	g.projectileCount = 0
 	g.score = 0
 	g.meteorSpawnTimer.Reset()
-	g.baseVelocity = baseMeteorVelocity
+	g.baseVelocity = g.startingVelocity()
 	g.velocityTimer.Reset()
 	g.playerIsDead = false
 	g.exhaust = nil
 	g.space.RemoveAll()
 	g.space.Add(g.player.playerObj)
-	g.stars = GenerateStars(numberOfStars)
-	g.player.shieldsRemaining = numberOfShields
+	if g.boss != nil && !g.boss.isDestroyed() {
+		g.space.Add(g.boss.shapes()...)
+	}
+	g.stars = GenerateStars(starCount(), g.random.stars)
+	g.player.shieldsRemaining = g.tuning.Player.Shields
 	g.player.isShielded = false
 	g.aliens = make(map[int]*Alien)
 	g.alienCount = 0
+	g.squadrons = nil
 	g.alienLasers = make(map[int]*AlienLaser)
+	g.blasts = nil
+	g.powerUps = make(map[int]*PowerUp)
 	g.alienLaserCount = 0
+	g.phase = PhasePlaying
 }
@@ -0,0 +1,139 @@
+package goasteroids
+
+import (
+	"testing"
+	"time"
+)
+
+// spinAndShoot is the input for tick n of a game played by turning on the spot and firing as fast
+// as the fire button can be tapped.
+func spinAndShoot(n int) InputFrame {
+	frame := InputFrame(0).With(ActionRotateLeft)
+	if n%2 == 0 {
+		frame = frame.With(ActionFire)
+	}
+	return frame
+}
+
+// playFirstLevel steps a headless game from seed until the first level's cleared or the game's
+// over, giving up after five minutes of play. It returns the game and how many ticks it took.
+func playFirstLevel(t *testing.T, seed uint64) (*GameScene, int) {
+	t.Helper()
+
+	g := NewHeadlessGameScene(seed, DifficultyNormal, classicLevels())
+	for tick := 0; tick < 5*60*TicksPerSecond; tick++ {
+		g.Step(spinAndShoot(tick))
+		if g.Phase() != PhasePlaying {
+			return g, tick + 1
+		}
+	}
+	t.Fatalf("seed %d: the first level was still going after five minutes", seed)
+	return nil, 0
+}
+
+func TestStepClearsLevel(t *testing.T) {
+	g, ticks := playFirstLevel(t, 42)
+
+	if g.Phase() != PhaseLevelStarting {
+		t.Fatalf("phase after %d ticks = %d, want %d (level starting)", ticks, g.Phase(), PhaseLevelStarting)
+	}
+	if g.Level() != 2 {
+		t.Errorf("level = %d, want 2, the one about to start", g.Level())
+	}
+	if g.Score() != 31 {
+		t.Errorf("score = %d, want 31", g.Score())
+	}
+	if len(g.Replay().Frames) != ticks {
+		t.Errorf("replay has %d frames, want one for each of the %d ticks", len(g.Replay().Frames), ticks)
+	}
+
+	// Tapping fire skips the pause, so the next level starts on the following tick.
+	g.Step(InputFrame(0).With(ActionFire))
+	if g.Phase() != PhasePlaying || g.Level() != 2 {
+		t.Errorf("after skipping the pause: phase %d, level %d, want %d, 2", g.Phase(), g.Level(), PhasePlaying)
+	}
+}
+
+func TestStepIsDeterministic(t *testing.T) {
+	for _, seed := range []uint64{1, 2, 42} {
+		first, firstTicks := playFirstLevel(t, seed)
+		second, secondTicks := playFirstLevel(t, seed)
+
+		if firstTicks != secondTicks || first.Score() != second.Score() || first.Phase() != second.Phase() {
+			t.Errorf("seed %d played out differently: %d ticks, score %d, phase %d, then %d ticks, score %d, phase %d",
+				seed, firstTicks, first.Score(), first.Phase(), secondTicks, second.Score(), second.Phase())
+		}
+	}
+}
+
+// emptyGame returns a headless game with its meteors and aliens cleared away, so a test can put
+// things just where it wants them. It doesn't hold off the timers that bring more, so tests that
+// step it should be short.
+func emptyGame(t *testing.T) *GameScene {
+	t.Helper()
+
+	g := NewHeadlessGameScene(1, DifficultyNormal, classicLevels())
+	for k, m := range g.meteors {
+		g.space.Remove(m.meteorObj)
+		delete(g.meteors, k)
+	}
+	for k, a := range g.aliens {
+		g.space.Remove(a.alienObj)
+		delete(g.aliens, k)
+	}
+	return g
+}
+
+// placeMeteor puts a still meteor of size in g, with its middle at centre.
+func placeMeteor(g *GameScene, size MeteorSize, centre Vector) *Meteor {
+	m := newMeteor(size, 0, EdgeAny, g, 0, g.random.meteors)
+	bounds := m.sprite.Bounds()
+	m.place(Vector{X: centre.X - float64(bounds.Dx())/2, Y: centre.Y - float64(bounds.Dy())/2}, nil, 0)
+	g.addMeteor(m)
+	return m
+}
+
+func TestWaveSendsLaterGroupsAfterASplit(t *testing.T) {
+	// A large meteor, then a medium one. Breaking the first before the second is sent makes more
+	// meteors than the wave has, but the second should still come.
+	corner := Vector{X: 40, Y: 40}
+	levels := &LevelSet{
+		Version: levelSetVersion,
+		Name:    "two-groups",
+		Levels: []Level{{Waves: []Wave{{
+			SpawnTime: Duration(time.Second),
+			Meteors: []MeteorGroup{
+				{Count: 1, Size: MeteorLarge, Material: MaterialRock, At: &corner},
+				{Count: 1, Size: MeteorMedium, Material: MaterialRock, At: &corner},
+			},
+		}}}},
+	}
+	g := NewHeadlessGameScene(1, DifficultyNormal, levels)
+
+	if !stepUntil(g, func() bool { return g.waveSpawned == 1 }) {
+		t.Fatal("the first meteor never came")
+	}
+	for _, k := range inOrder(g.meteors) {
+		m := g.meteors[k]
+		g.hitMeteor(m, m.centre(), creditNobody)
+	}
+	if g.meteorCount <= g.currentWave().size() {
+		t.Fatalf("%d meteors made after the split, want more than the wave's %d", g.meteorCount, g.currentWave().size())
+	}
+
+	if !stepUntil(g, func() bool { return g.waveSpawned == 2 }) {
+		t.Fatal("the second group never came")
+	}
+	if g.Phase() != PhasePlaying || g.Level() != 1 {
+		t.Errorf("phase %d, level %d once the second group came, want %d, 1", g.Phase(), g.Level(), PhasePlaying)
+	}
+	mediums := 0
+	for _, m := range g.meteors {
+		if m.size == MeteorMedium {
+			mediums++
+		}
+	}
+	if want := g.tuning.Meteors.PiecesPerSplit + 1; mediums != want {
+		t.Errorf("%d medium meteors, want %d: the pieces and the second group's", mediums, want)
+	}
+}
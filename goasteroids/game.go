@@ -1,39 +1,74 @@
 package goasteroids
 
-import "github.com/hajimehoshi/ebiten/v2"
+import (
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Version is the version of the game. It's stored in replays, so we can tell where they came from.
+const Version = "1.0.0"
 
 // Game is the type for the overall game. It holds a scene manager, used to change scenes,
-// and a stub input type (required to use this as a parameter in ebiten.RunGame) which is
-// used to pass keyboard input to scenes.
+// the input, which turns key presses into actions and is passed to scenes, and the gamepads
+// the input picks from.
 type Game struct {
+	Seed         uint64 // If set, every game is played with this seed (e.g. the daily challenge) instead of a new one.
+	ReplayPath   string // If set, we start by watching this replay instead of at the title scene.
+	EditLevels   string // If set, we start in the level editor, editing the level set with this name.
 	sceneManager *SceneManager
-	input        Input
+	input        *Input
+	gamepads     *Gamepads
 }
 
-// Input is a stub type so that we can use Game as an ebiten.Game interface in the call to ebiten.RunGame.
-type Input struct{}
-
-// Update is required to satisfy the interface requirements for calling ebiten.RunGame. It's a stub, but we need it.
-func (i *Input) Update() {}
-
 // Update manages scenes, and updates input (which is sent to each scene).
 func (g *Game) Update() error {
 	if g.sceneManager == nil {
+		g.gamepads = NewGamepads()
+		g.input = NewInput(g.gamepads)
 		g.sceneManager = &SceneManager{}
-		meteors := make(map[int]*Meteor)
-		g.sceneManager.GoToScene(&TitleScene{
-			meteors: meteors,
-			stars:   GenerateStars(numberOfStars),
-		})
+		g.sceneManager.GoToScene(g.firstScene())
+	}
+
+	// Designers can change the tuning file and reload it without restarting.
+	if inpututil.IsKeyJustPressed(ebiten.KeyF5) {
+		if err := ReloadTuning(); err != nil {
+			log.Println("Error reloading tuning:", err)
+		} else {
+			log.Println("Reloaded tuning")
+		}
 	}
 
 	g.input.Update()
-	if err := g.sceneManager.Update(&g.input); err != nil {
+	if err := g.sceneManager.Update(g.input); err != nil {
 		return err
 	}
 	return nil
 }
 
+// firstScene returns the scene the game opens on: the level editor or the replay we were asked
+// for, or the title.
+func (g *Game) firstScene() Scene {
+	if g.EditLevels != "" {
+		e, err := NewLevelEditorScene(g.EditLevels)
+		if err == nil {
+			return e
+		}
+		log.Println("Error opening level editor:", err)
+	}
+
+	if g.ReplayPath != "" {
+		r, err := LoadReplay(g.ReplayPath)
+		if err == nil {
+			return NewReplayScene(r)
+		}
+		log.Println("Error loading replay:", err)
+	}
+
+	return NewTitleScene(g.Seed)
+}
+
 // Draw draws the game using the current scene.
 func (g *Game) Draw(screen *ebiten.Image) {
 	g.sceneManager.Draw(screen)
@@ -0,0 +1,127 @@
+package goasteroids
+
+import (
+	"log"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	gamepadDeadZone = 0.25 // Stick movement smaller than this is ignored, so worn sticks don't drift the ship.
+	stickThreshold  = 0.5  // How far a stick has to be pushed (after the dead zone) to count as held.
+)
+
+// gamepadButtons maps the buttons of a standard gamepad to actions. The face buttons are named by
+// where they sit, so RightBottom is "A" on an Xbox pad and "Cross" on a PlayStation one.
+var gamepadButtons = map[ebiten.StandardGamepadButton][]Action{
+	ebiten.StandardGamepadButtonRightBottom:   {ActionFire, ActionConfirm},
+	ebiten.StandardGamepadButtonRightRight:    {ActionShield},
+	ebiten.StandardGamepadButtonRightTop:      {ActionHyperspace},
+	ebiten.StandardGamepadButtonCenterRight:   {ActionPause, ActionConfirm},
+	ebiten.StandardGamepadButtonFrontTopRight: {ActionCycleWeapon},
+	ebiten.StandardGamepadButtonLeftLeft:      {ActionRotateLeft},
+	ebiten.StandardGamepadButtonLeftRight:     {ActionRotateRight},
+	ebiten.StandardGamepadButtonLeftTop:       {ActionThrust},
+	ebiten.StandardGamepadButtonLeftBottom:    {ActionReverse},
+}
+
+// Gamepads keeps track of which gamepads are assigned to a player, so two players never share
+// one. Game owns it and hands it to the Input it creates.
+type Gamepads struct {
+	claimed map[ebiten.GamepadID]bool // The gamepads already assigned to a player.
+}
+
+// NewGamepads is a factory method which creates a registry with every gamepad free.
+func NewGamepads() *Gamepads {
+	return &Gamepads{claimed: make(map[ebiten.GamepadID]bool)}
+}
+
+// claim assigns the first free gamepad with a standard layout, if there is one.
+func (p *Gamepads) claim() (ebiten.GamepadID, bool) {
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if p.claimed[id] || !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		p.claimed[id] = true
+		return id, true
+	}
+	return 0, false
+}
+
+// release frees gamepad id for the next player who needs one.
+func (p *Gamepads) release(id ebiten.GamepadID) {
+	delete(p.claimed, id)
+}
+
+// updateGamepad keeps track of gamepads being plugged in and pulled out. A player without a
+// gamepad gets the first free one, so plugging one in mid-game just works. An input without a
+// registry never gets one.
+func (i *Input) updateGamepad() {
+	if i.gamepads == nil {
+		return
+	}
+
+	for _, id := range inpututil.AppendJustConnectedGamepadIDs(nil) {
+		log.Printf("Gamepad connected: %s", ebiten.GamepadName(id))
+	}
+
+	if i.hasGamepad && inpututil.IsGamepadJustDisconnected(i.gamepad) {
+		log.Printf("Gamepad disconnected: %d", i.gamepad)
+		i.gamepads.release(i.gamepad)
+		i.hasGamepad = false
+	}
+
+	if !i.hasGamepad {
+		i.gamepad, i.hasGamepad = i.gamepads.claim()
+	}
+}
+
+// gamepadFrame reads the player's gamepad into an InputFrame.
+func (i *Input) gamepadFrame() InputFrame {
+	var frame InputFrame
+	if !i.hasGamepad {
+		return frame
+	}
+
+	for button, actions := range gamepadButtons {
+		if ebiten.IsStandardGamepadButtonPressed(i.gamepad, button) {
+			for _, a := range actions {
+				frame = frame.With(a)
+			}
+		}
+	}
+
+	// The left stick steers: left and right rotate, up thrusts and down reverses.
+	x, y := applyDeadZone(
+		ebiten.StandardGamepadAxisValue(i.gamepad, ebiten.StandardGamepadAxisLeftStickHorizontal),
+		ebiten.StandardGamepadAxisValue(i.gamepad, ebiten.StandardGamepadAxisLeftStickVertical),
+	)
+	if x < -stickThreshold {
+		frame = frame.With(ActionRotateLeft)
+	}
+	if x > stickThreshold {
+		frame = frame.With(ActionRotateRight)
+	}
+	if y < -stickThreshold {
+		frame = frame.With(ActionThrust)
+	}
+	if y > stickThreshold {
+		frame = frame.With(ActionReverse)
+	}
+
+	return frame
+}
+
+// applyDeadZone ignores stick movements closer to the middle than gamepadDeadZone, and rescales
+// the rest so the stick still runs from 0 to 1 beyond it.
+func applyDeadZone(x, y float64) (float64, float64) {
+	magnitude := math.Hypot(x, y)
+	if magnitude < gamepadDeadZone {
+		return 0, 0
+	}
+
+	scale := (math.Min(magnitude, 1) - gamepadDeadZone) / (1 - gamepadDeadZone) / magnitude
+	return x * scale, y * scale
+}
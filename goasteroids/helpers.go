@@ -1,80 +1,104 @@
 package goasteroids
 
 import (
+	"asteroids/internal/atomicfile"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/user"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
 )
 
-func getHighScore() (int, error) {
-	// Get the username.
+// highScorePath returns where the high score is kept.
+func highScorePath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "high-score.txt"), nil
+}
+
+// legacyHighScorePath returns where older versions of the game kept the high score.
+func legacyHighScorePath() (string, error) {
 	u, err := user.Current()
 	if err != nil {
-		return 0, err
+		return "", err
 	}
 
-	path := ""
 	switch runtime.GOOS {
 	case "darwin":
-		path = fmt.Sprintf("/Users/%s/Library/Application Support/Go Asteroids", u.Username)
+		return fmt.Sprintf("/Users/%s/Library/Application Support/Go Asteroids/high-score.txt", u.Username), nil
 	case "windows":
-		path = fmt.Sprintf("C:\\Users\\%s\\AppData", u.Username)
+		return fmt.Sprintf("C:\\Users\\%s\\AppData\\high-score.txt", u.Username), nil
 	default:
-		path = fmt.Sprintf("/users/%s", u.Username)
+		return fmt.Sprintf("/users/%s/high-score.txt", u.Username), nil
 	}
+}
 
-	if _, err := os.Stat(path); err != nil {
-		if err := os.Mkdir(path, 0750); err != nil {
-			return 0, err
-		}
+// readHighScore reads a high score file.
+func readHighScore(path string) (int, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
 	}
 
-	if _, err := os.Stat(path + "/high-score.txt"); err != nil {
-		err := os.WriteFile(path + "/high-score.txt", []byte("0"), 0640)
-		if err != nil {
-			return 0, err
-		}
+	s, err := strconv.Atoi(strings.TrimSpace(string(contents)))
+	if err != nil {
+		return 0, fmt.Errorf("reading %s: %w", path, err)
 	}
+	return s, nil
+}
 
-	contents, err := os.ReadFile(path + "/high-score.txt")
+// getHighScore returns the high score. If there isn't one yet but an older version of the game
+// left one behind, it's copied over (and the old file is left alone). A file that can't be read
+// as a score is set aside rather than overwritten.
+func getHighScore() (int, error) {
+	path, err := highScorePath()
 	if err != nil {
 		return 0, err
 	}
-	score := string(contents)
-	score = strings.TrimSpace(score)
-	s, err := strconv.Atoi(string(score))
-	if err != nil {
+
+	s, err := readHighScore(path)
+	switch {
+	case err == nil:
+		return s, nil
+	case errors.Is(err, fs.ErrNotExist):
+		return migrateHighScore(path)
+	case errors.Is(err, strconv.ErrSyntax) || errors.Is(err, strconv.ErrRange):
+		return 0, fmt.Errorf("%w (kept as %s)", err, setAsideCorrupt(path))
+	default:
 		return 0, err
 	}
-
-	return s, nil
 }
 
-func updateHighScore(score int) error {
-	// Get the username.
-	u, err := user.Current()
-	if err != nil {
-		return err
+// migrateHighScore copies the high score from where older versions kept it to path.
+func migrateHighScore(path string) (int, error) {
+	legacy, err := legacyHighScorePath()
+	if err != nil || legacy == path {
+		return 0, err
 	}
 
-	
-	path := ""
-	switch runtime.GOOS {
-	case "darwin":
-		path = fmt.Sprintf("/Users/%s/Library/Application Support/Go Asteroids/high-score.txt", u.Username)
-	case "windows":
-		path = fmt.Sprintf("C:\\Users\\%s\\AppData\\high-score.txt", u.Username)
-	default:
-		path = fmt.Sprintf("/users/%s/high-score.txt", u.Username)
+	s, err := readHighScore(legacy)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
 	}
+	if err != nil {
+		return 0, err
+	}
+
+	return s, updateHighScore(s)
+}
 
-	s := fmt.Sprintf("%d", score)
-	if err := os.WriteFile(path, []byte(s), 0640); err != nil {
+// updateHighScore saves score as the high score.
+func updateHighScore(score int) error {
+	path, err := highScorePath()
+	if err != nil {
 		return err
 	}
 
-	return nil
-}
\ No newline at end of file
+	return atomicfile.WriteFile(path, []byte(strconv.Itoa(score)), 0640)
+}
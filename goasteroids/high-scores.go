@@ -0,0 +1,170 @@
+package goasteroids
+
+import (
+	"asteroids/internal/atomicfile"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const (
+	highScoresVersion = 1  // Bump this when the layout of the high score file changes.
+	maxHighScores     = 10 // How many entries the table keeps.
+	initialsLength    = 3  // How many letters of initials an entry has.
+)
+
+// HighScore is one entry in the high score table.
+type HighScore struct {
+	Initials string    `json:"initials"`
+	Score    int       `json:"score"`
+	Level    int       `json:"level"` // The level the player reached.
+	Date     time.Time `json:"date"`
+	Mode     Mode      `json:"mode"`
+}
+
+// HighScores is the high score table, best first.
+type HighScores []HighScore
+
+// highScoresFile is the layout of the high score file.
+type highScoresFile struct {
+	Version int        `json:"version"`
+	Entries HighScores `json:"entries"`
+}
+
+var highScores HighScores // The table in use. It's empty until UseHighScores is called.
+
+// UseHighScores makes hs the high score table the game shows and adds to.
+func UseHighScores(hs HighScores) {
+	highScores = hs
+}
+
+// highScoresPath returns where the high score table is kept.
+func highScoresPath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "high-scores.json"), nil
+}
+
+// LoadHighScores reads the high score table. If there isn't one yet, the single high score kept
+// by older versions of the game becomes its first entry.
+func LoadHighScores() (HighScores, error) {
+	path, err := highScoresPath()
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return migrateHighScores()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A file we can't read, or one from another version, is set aside rather than lost the next
+	// time we save.
+	var file highScoresFile
+	if err := json.Unmarshal(contents, &file); err != nil {
+		return nil, fmt.Errorf("reading %s: %w (kept as %s)", path, err, setAsideCorrupt(path))
+	}
+	if file.Version != highScoresVersion {
+		return nil, fmt.Errorf("reading %s: unsupported version %d (kept as %s)", path, file.Version, setAsideVersion(path, file.Version))
+	}
+
+	file.Entries.sort()
+	if len(file.Entries) > maxHighScores {
+		file.Entries = file.Entries[:maxHighScores]
+	}
+	return file.Entries, nil
+}
+
+// migrateHighScores turns the old high-score.txt into a table, and saves it.
+func migrateHighScores() (HighScores, error) {
+	score, err := getHighScore()
+	if err != nil || score == 0 {
+		return nil, err
+	}
+
+	hs := HighScores{{
+		Initials: "???",
+		Score:    score,
+		Level:    1,
+		Date:     time.Now(),
+		Mode:     ModeClassic,
+	}}
+	return hs, SaveHighScores(hs)
+}
+
+// SaveHighScores writes the high score table.
+func SaveHighScores(hs HighScores) error {
+	path, err := highScoresPath()
+	if err != nil {
+		return err
+	}
+
+	contents, err := json.MarshalIndent(highScoresFile{
+		Version: highScoresVersion,
+		Entries: hs,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(path, contents, 0640)
+}
+
+// Best returns the top score in the table, or 0 if it's empty.
+func (hs HighScores) Best() int {
+	if len(hs) == 0 {
+		return 0
+	}
+	return hs[0].Score
+}
+
+// Qualifies reports whether score is good enough to go in the table.
+func (hs HighScores) Qualifies(score int) bool {
+	if score <= 0 {
+		return false
+	}
+	return len(hs) < maxHighScores || score > hs[len(hs)-1].Score
+}
+
+// Insert returns the table with e added, and where e ended up (0 is the top). If e didn't make
+// the cut, the table is returned unchanged with a rank of -1.
+func (hs HighScores) Insert(e HighScore) (HighScores, int) {
+	if !hs.Qualifies(e.Score) {
+		return hs, -1
+	}
+
+	// Ties go below the entries already there; they got the score first.
+	rank, _ := slices.BinarySearchFunc(hs, e.Score, func(h HighScore, score int) int {
+		if h.Score >= score {
+			return -1
+		}
+		return 1
+	})
+
+	hs = slices.Insert(slices.Clone(hs), rank, e)
+	if len(hs) > maxHighScores {
+		hs = hs[:maxHighScores]
+	}
+	return hs, rank
+}
+
+// sort puts the table in order: best score first, and the earlier of two equal scores first.
+func (hs HighScores) sort() {
+	slices.SortStableFunc(hs, func(a, b HighScore) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return a.Date.Compare(b.Date)
+	})
+}
@@ -0,0 +1,180 @@
+package goasteroids
+
+import (
+	"asteroids/assets"
+	"fmt"
+	"image/color"
+	"log"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+var lastInitials = [initialsLength]byte{'A', 'A', 'A'} // Start from the initials entered last time.
+
+// InitialsEntryScene asks a player whose score made the high score table (or any player, if there's
+// a shared leaderboard) for their initials, one letter at a time, arcade style: up and down change
+// the letter, left and right move between letters, and confirm moves on (or finishes, on the last
+// letter). Letters can be typed too.
+type InitialsEntryScene struct {
+	game     *GameScene           // The game that made the table.
+	initials [initialsLength]byte // The letters so far.
+	cursor   int                  // The letter being changed.
+	stars    []*Star              // A slice of stars.
+	keys     []ebiten.Key         // Reused every tick to read key presses.
+}
+
+// NewInitialsEntryScene creates an initials entry screen for the player of game g.
+func NewInitialsEntryScene(g *GameScene) *InitialsEntryScene {
+	return &InitialsEntryScene{
+		game:     g,
+		initials: lastInitials,
+		stars:    GenerateStars(starCount(), menuRand),
+	}
+}
+
+// Update changes the letters. It's called once per tick.
+func (e *InitialsEntryScene) Update(state *State) error {
+	// Typing a letter sets it and moves on. Letters may be bound to actions too (WASD, say), so
+	// when one's typed we don't look at the actions.
+	e.keys = inpututil.AppendJustPressedKeys(e.keys[:0])
+	for _, k := range e.keys {
+		if k >= ebiten.KeyA && k <= ebiten.KeyZ {
+			e.initials[e.cursor] = byte('A' + k - ebiten.KeyA)
+			if e.cursor == initialsLength-1 {
+				e.finish(state)
+			} else {
+				e.cursor++
+			}
+			return nil
+		}
+	}
+
+	if state.Input.IsJustPressed(ActionThrust) {
+		e.changeLetter(1)
+	}
+	if state.Input.IsJustPressed(ActionReverse) {
+		e.changeLetter(-1)
+	}
+	if state.Input.IsJustPressed(ActionRotateLeft) || inpututil.IsKeyJustPressed(ebiten.KeyBackspace) {
+		e.cursor = max(e.cursor-1, 0)
+	}
+	if state.Input.IsJustPressed(ActionRotateRight) {
+		e.cursor = min(e.cursor+1, initialsLength-1)
+	}
+
+	if state.Input.IsJustPressed(ActionConfirm) {
+		if e.cursor == initialsLength-1 {
+			e.finish(state)
+			return nil
+		}
+		e.cursor++
+	}
+
+	return nil
+}
+
+// wantsInitials reports whether a game that scored score should ask for the player's initials: if
+// it made our table, or there's a shared leaderboard to send it to.
+func wantsInitials(score int) bool {
+	return highScores.Qualifies(score) || onlineBoard != nil && score > 0
+}
+
+// changeLetter moves the current letter step places through the alphabet, wrapping around.
+func (e *InitialsEntryScene) changeLetter(step int) {
+	e.initials[e.cursor] = byte('A' + (int(e.initials[e.cursor]-'A')+step+26)%26)
+}
+
+// finish puts the score in the table, saves it, and shows it.
+func (e *InitialsEntryScene) finish(state *State) {
+	lastInitials = e.initials
+	rank := recordScore(e.game, e.initials)
+	state.SceneManager.GoToScene(NewLeaderboardScene(e.game.titleSeed(), rank))
+}
+
+// recordScore puts the score of game g in the table under initials, saves it, and sends it to the
+// shared leaderboard if there is one. It returns the score's place in the table.
+func recordScore(g *GameScene, initials [initialsLength]byte) int {
+	var rank int
+	highScores, rank = highScores.Insert(HighScore{
+		Initials: string(initials[:]),
+		Score:    g.Score(),
+		Level:    g.Level(),
+		Date:     time.Now(),
+		Mode:     g.Mode(),
+	})
+	if err := SaveHighScores(highScores); err != nil {
+		log.Println("Error saving high scores:", err)
+	}
+	submitOnline(g, string(initials[:]))
+	return rank
+}
+
+// Draw draws the letters, with the current one underlined. It's called once per frame.
+func (e *InitialsEntryScene) Draw(screen *ebiten.Image) {
+	// Draw stars.
+	for _, s := range e.stars {
+		s.Draw(screen)
+	}
+
+	op := &text.DrawOptions{
+		LayoutOptions: text.LayoutOptions{
+			PrimaryAlign: text.AlignCenter,
+		},
+	}
+	op.ColorScale.ScaleWithColor(color.White)
+	op.GeoM.Translate(ScreenWidth/2, ScreenHeight/2-250)
+	text.Draw(screen, "New High Score!", &text.GoTextFace{
+		Source: assets.TitleFont,
+		Size:   48,
+	}, op)
+
+	op = &text.DrawOptions{
+		LayoutOptions: text.LayoutOptions{
+			PrimaryAlign: text.AlignCenter,
+		},
+	}
+	op.ColorScale.ScaleWithColor(color.White)
+	op.GeoM.Translate(ScreenWidth/2, ScreenHeight/2-150)
+	text.Draw(screen, fmt.Sprintf("%06d   ENTER YOUR INITIALS", e.game.Score()), &text.GoTextFace{
+		Source: assets.ScoreFont,
+		Size:   24,
+	}, op)
+
+	for i, letter := range e.initials {
+		textToDraw := string(letter)
+		if i == e.cursor {
+			textToDraw = "_" + textToDraw + "_"
+		}
+
+		op = &text.DrawOptions{
+			LayoutOptions: text.LayoutOptions{
+				PrimaryAlign: text.AlignCenter,
+			},
+		}
+		op.ColorScale.ScaleWithColor(color.White)
+		if i != e.cursor {
+			op.ColorScale.ScaleAlpha(0.6)
+		}
+		op.GeoM.Translate(ScreenWidth/2+float64(i-1)*120, ScreenHeight/2)
+		text.Draw(screen, textToDraw, &text.GoTextFace{
+			Source: assets.TitleFont,
+			Size:   64,
+		}, op)
+	}
+
+	op = &text.DrawOptions{
+		LayoutOptions: text.LayoutOptions{
+			PrimaryAlign: text.AlignCenter,
+		},
+	}
+	op.ColorScale.ScaleWithColor(color.White)
+	op.ColorScale.ScaleAlpha(0.5)
+	op.GeoM.Translate(ScreenWidth/2, ScreenHeight/2+200)
+	text.Draw(screen, "UP/DOWN CHANGE   LEFT/RIGHT MOVE   CONFIRM TO ENTER", &text.GoTextFace{
+		Source: assets.ScoreFont,
+		Size:   16,
+	}, op)
+}
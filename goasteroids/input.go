@@ -0,0 +1,204 @@
+package goasteroids
+
+import (
+	"asteroids/internal/atomicfile"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const bindingsVersion = 1 // Bump this when the layout of the controls file changes.
+
+// Bindings maps each action to the keys that trigger it.
+type Bindings map[Action][]ebiten.Key
+
+// bindingsFile is the layout of the controls file.
+type bindingsFile struct {
+	Version  int      `json:"version"`
+	Bindings Bindings `json:"bindings"`
+}
+
+// actionNames are the names used for actions in the controls file.
+var actionNames = map[Action]string{
+	ActionRotateLeft:  "rotate-left",
+	ActionRotateRight: "rotate-right",
+	ActionThrust:      "thrust",
+	ActionReverse:     "reverse",
+	ActionFire:        "fire",
+	ActionShield:      "shield",
+	ActionHyperspace:  "hyperspace",
+	ActionPause:       "pause",
+	ActionConfirm:     "confirm",
+	ActionCycleWeapon: "cycle-weapon",
+}
+
+// String returns the action's name, as used in the controls file.
+func (a Action) String() string {
+	if name, ok := actionNames[a]; ok {
+		return name
+	}
+	return fmt.Sprintf("Action(%d)", int(a))
+}
+
+// MarshalText implements encoding.TextMarshaler, so actions can be used as JSON keys.
+func (a Action) MarshalText() ([]byte, error) {
+	if _, ok := actionNames[a]; !ok {
+		return nil, fmt.Errorf("unknown action %d", int(a))
+	}
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (a *Action) UnmarshalText(text []byte) error {
+	for action, name := range actionNames {
+		if name == string(text) {
+			*a = action
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown action %q", text)
+}
+
+// DefaultBindings returns the controls the game ships with.
+func DefaultBindings() Bindings {
+	return Bindings{
+		ActionRotateLeft:  {ebiten.KeyLeft},
+		ActionRotateRight: {ebiten.KeyRight},
+		ActionThrust:      {ebiten.KeyUp},
+		ActionReverse:     {ebiten.KeyDown},
+		ActionFire:        {ebiten.KeySpace},
+		ActionShield:      {ebiten.KeyS},
+		ActionHyperspace:  {ebiten.KeyH},
+		ActionPause:       {ebiten.KeyEscape, ebiten.KeyP},
+		ActionConfirm:     {ebiten.KeySpace, ebiten.KeyEnter},
+		ActionCycleWeapon: {ebiten.KeyC},
+	}
+}
+
+// bindingsPath returns where the controls file lives.
+func bindingsPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "controls.json"), nil
+}
+
+// LoadBindings reads the player's controls file. Any action the file doesn't mention keeps its
+// default keys, and if there's no file at all we just use the defaults.
+func LoadBindings() (Bindings, error) {
+	bindings := DefaultBindings()
+
+	path, err := bindingsPath()
+	if err != nil {
+		return bindings, err
+	}
+
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return bindings, nil
+	}
+	if err != nil {
+		return bindings, err
+	}
+
+	var file bindingsFile
+	// A file we can't read, or one from another version, is set aside rather than lost the next
+	// time we save.
+	if err := json.Unmarshal(contents, &file); err != nil {
+		return bindings, fmt.Errorf("reading %s: %w (kept as %s)", path, err, setAsideCorrupt(path))
+	}
+	if file.Version != bindingsVersion {
+		return bindings, fmt.Errorf("reading %s: unsupported version %d (kept as %s)", path, file.Version, setAsideVersion(path, file.Version))
+	}
+
+	for action, keys := range file.Bindings {
+		bindings[action] = keys
+	}
+
+	return bindings, nil
+}
+
+// SaveBindings writes bindings to the player's controls file.
+func SaveBindings(bindings Bindings) error {
+	path, err := bindingsPath()
+	if err != nil {
+		return err
+	}
+
+	contents, err := json.MarshalIndent(bindingsFile{
+		Version:  bindingsVersion,
+		Bindings: bindings,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(path, contents, 0640)
+}
+
+// Input turns key presses and gamepad buttons into actions for one player. Game updates it once
+// per tick, and it's handed to every scene through State.
+type Input struct {
+	bindings   Bindings         // Which keys trigger which action.
+	frame      InputFrame       // The actions held down this tick.
+	lastFrame  InputFrame       // The actions held down last tick.
+	gamepads   *Gamepads        // Where this player's gamepad comes from.
+	gamepad    ebiten.GamepadID // The gamepad assigned to this player, if hasGamepad is set.
+	hasGamepad bool             // Does this player have a gamepad?
+}
+
+// NewInput creates an input that uses the player's controls file, falling back to the default
+// controls if it can't be read, and takes a free gamepad from gamepads when one's plugged in.
+func NewInput(gamepads *Gamepads) *Input {
+	bindings, err := LoadBindings()
+	if err != nil {
+		log.Println("Error loading controls:", err)
+	}
+
+	return &Input{bindings: bindings, gamepads: gamepads}
+}
+
+// Update reads the keyboard and the player's gamepad into this tick's frame.
+func (i *Input) Update() {
+	i.lastFrame = i.frame
+	i.frame = 0
+
+	for action, keys := range i.bindings {
+		for _, k := range keys {
+			if ebiten.IsKeyPressed(k) {
+				i.frame = i.frame.With(action)
+				break
+			}
+		}
+	}
+
+	i.updateGamepad()
+	i.frame |= i.gamepadFrame()
+}
+
+// Frame returns the actions held down this tick.
+func (i *Input) Frame() InputFrame {
+	return i.frame
+}
+
+// IsJustPressed reports whether action a went down this tick.
+func (i *Input) IsJustPressed(a Action) bool {
+	return i.frame.Has(a) && !i.lastFrame.Has(a)
+}
+
+// Bindings returns the keys bound to each action.
+func (i *Input) Bindings() Bindings {
+	return i.bindings
+}
+
+// SetBindings changes the keys bound to each action.
+func (i *Input) SetBindings(bindings Bindings) {
+	i.bindings = bindings
+}
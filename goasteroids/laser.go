@@ -8,15 +8,12 @@ import (
 	"github.com/solarlune/resolv"
 )
 
-const (
-	laserSpeedPerSecond = 1000.0
-)
-
 type Laser struct {
 	game     *GameScene
 	position Vector
 	rotation float64
 	sprite   *ebiten.Image
+	spent    bool
 	laserObj *resolv.ConvexPolygon
 }
 
@@ -51,7 +48,7 @@ func NewLaser(pos Vector, rotation float64, index int, g *GameScene) *Laser {
 
 func (l *Laser) Update() {
 	// How fast should the laser go.
-	speed := laserSpeedPerSecond / float64(ebiten.TPS())
+	speed := l.game.tuning.Player.LaserSpeed / TicksPerSecond
 	dx := math.Sin(l.rotation) * speed
 	dy := math.Cos(l.rotation) * -speed
 
@@ -76,3 +73,34 @@ func (l *Laser) Draw(screen *ebiten.Image) {
 	screen.DrawImage(l.sprite, op)
 	
 }
+
+func (l *Laser) shape() resolv.IShape {
+	return l.laserObj
+}
+
+// point returns the middle of the laser.
+func (l *Laser) point() Vector {
+	bounds := l.sprite.Bounds()
+	return Vector{
+		X: l.position.X + float64(bounds.Dx())/2,
+		Y: l.position.Y + float64(bounds.Dy())/2,
+	}
+}
+
+// strike hits target if the laser touches it. A laser's used up by the first thing it hits.
+func (l *Laser) strike(target resolv.IShape) bool {
+	if l.spent || !l.laserObj.IsIntersecting(target) {
+		return false
+	}
+	l.spent = true
+	return true
+}
+
+func (l *Laser) isSpent() bool {
+	return l.spent || isOffscreen(l.position)
+}
+
+// isOffscreen reports whether pos is far enough off the screen that whatever's there won't be back.
+func isOffscreen(pos Vector) bool {
+	return pos.X > ScreenWidth+200 || pos.Y > ScreenHeight+200 || pos.X < -200 || pos.Y < -200
+}
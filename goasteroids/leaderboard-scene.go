@@ -0,0 +1,162 @@
+package goasteroids
+
+import (
+	"asteroids/assets"
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+const attractTime = 10 * time.Second // How long the title and the high score table show before swapping.
+
+// leaderboardColumns are the x positions of the table's columns, and their headings.
+var leaderboardColumns = []struct {
+	x       float64
+	heading string
+}{
+	{ScreenWidth/2 - 520, "RANK"},
+	{ScreenWidth/2 - 380, "NAME"},
+	{ScreenWidth/2 - 220, "SCORE"},
+	{ScreenWidth/2 - 20, "LEVEL"},
+	{ScreenWidth/2 + 120, "MODE"},
+	{ScreenWidth/2 + 300, "DATE"},
+}
+
+// LeaderboardScene shows the high score table, or the shared one from the leaderboard server. They
+// take turns with the title scene while nobody's playing, and ours is shown after the player
+// enters their initials.
+type LeaderboardScene struct {
+	seed         uint64  // The seed to hand back to the title scene.
+	highlight    int     // The entry to highlight (the one just made), or -1.
+	global       bool    // Are we showing the shared table rather than ours?
+	stars        []*Star // A slice of stars.
+	attractTimer *Timer  // How long until we move on.
+}
+
+// NewLeaderboardScene creates a high score table with entry highlight picked out (-1 for none).
+// seed is passed back to the title scene when we're done.
+func NewLeaderboardScene(seed uint64, highlight int) *LeaderboardScene {
+	return &LeaderboardScene{
+		seed:         seed,
+		highlight:    highlight,
+		stars:        GenerateStars(starCount(), menuRand),
+		attractTimer: NewTimer(attractTime),
+	}
+}
+
+// NewGlobalLeaderboardScene creates a scene showing the shared table from the leaderboard server.
+func NewGlobalLeaderboardScene(seed uint64) *LeaderboardScene {
+	l := NewLeaderboardScene(seed, -1)
+	l.global = true
+	return l
+}
+
+// Update moves on after a while (to the shared table if there is one, otherwise back to the
+// title), or starts a game if the player presses confirm. It's called once per tick.
+func (l *LeaderboardScene) Update(state *State) error {
+	if state.Input.IsJustPressed(ActionConfirm) {
+		startGame(state, l.seed)
+		return nil
+	}
+
+	if state.Input.IsJustPressed(ActionPause) {
+		state.SceneManager.GoToScene(NewTitleScene(l.seed))
+		return nil
+	}
+
+	l.attractTimer.Update()
+	if l.attractTimer.IsReady() {
+		if !l.global && onlineBoard != nil {
+			state.SceneManager.GoToScene(NewGlobalLeaderboardScene(l.seed))
+		} else {
+			state.SceneManager.GoToScene(NewTitleScene(l.seed))
+		}
+	}
+
+	return nil
+}
+
+// Draw draws the table. It's called once per frame.
+func (l *LeaderboardScene) Draw(screen *ebiten.Image) {
+	// Draw stars.
+	for _, s := range l.stars {
+		s.Draw(screen)
+	}
+
+	entries, heading, empty := highScores, "HIGH SCORES", "NO SCORES YET"
+	if l.global {
+		var ok bool
+		entries, ok = globalHighScores()
+		heading = "LEADERBOARD"
+		if !ok {
+			empty = "CAN'T REACH THE LEADERBOARD"
+		}
+	}
+
+	op := &text.DrawOptions{
+		LayoutOptions: text.LayoutOptions{
+			PrimaryAlign: text.AlignCenter,
+		},
+	}
+	op.ColorScale.ScaleWithColor(color.White)
+	op.GeoM.Translate(ScreenWidth/2, 120)
+	text.Draw(screen, heading, &text.GoTextFace{
+		Source: assets.TitleFont,
+		Size:   48,
+	}, op)
+
+	y := 240.0
+	for i, c := range leaderboardColumns {
+		drawLeaderboardCell(screen, c.heading, i, y, 0.5)
+	}
+
+	if len(entries) == 0 {
+		op := &text.DrawOptions{
+			LayoutOptions: text.LayoutOptions{
+				PrimaryAlign: text.AlignCenter,
+			},
+		}
+		op.ColorScale.ScaleWithColor(color.White)
+		op.GeoM.Translate(ScreenWidth/2, y+80)
+		text.Draw(screen, empty, &text.GoTextFace{
+			Source: assets.ScoreFont,
+			Size:   24,
+		}, op)
+	}
+
+	for rank, e := range entries {
+		y += 50
+		alpha := float32(1)
+		if l.highlight >= 0 && rank != l.highlight {
+			alpha = 0.6
+		}
+
+		cells := []string{
+			fmt.Sprintf("%d", rank+1),
+			e.Initials,
+			fmt.Sprintf("%06d", e.Score),
+			fmt.Sprintf("%d", e.Level),
+			strings.ToUpper(string(e.Mode)),
+			e.Date.Format("2006-01-02"),
+		}
+		for i, cell := range cells {
+			drawLeaderboardCell(screen, cell, i, y, alpha)
+		}
+	}
+}
+
+// drawLeaderboardCell draws s in column column of the table, at height y.
+func drawLeaderboardCell(screen *ebiten.Image, s string, column int, y float64, alpha float32) {
+	op := &text.DrawOptions{}
+	op.ColorScale.ScaleWithColor(color.White)
+	op.ColorScale.ScaleAlpha(alpha)
+	op.GeoM.Translate(leaderboardColumns[column].x, y)
+	text.Draw(screen, s, &text.GoTextFace{
+		Source: assets.ScoreFont,
+		Size:   24,
+	}, op)
+}
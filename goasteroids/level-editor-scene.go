@@ -0,0 +1,539 @@
+package goasteroids
+
+import (
+	"asteroids/assets"
+	"asteroids/internal/atomicfile"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	editorArrowScale   = TicksPerSecond   // Velocity arrows show where a meteor will be a second later.
+	editorChanceStep   = 10               // How much - and = change an alien's chance by.
+	editorEveryStep    = time.Second      // How much , and . change the time between aliens by.
+	editorMessageTime  = 3 * time.Second  // How long messages stay up.
+	editorPreviewSeed  = 0x5eed           // Picks the meteor and alien sprites the editor shows.
+	editorPanelX       = 20.0             // Where the text down the left starts.
+	editorAlienPanelX  = ScreenWidth - 40 // Where the alien schedules are listed down the right.
+	editorAlienRowSize = 70.0             // The gap between alien schedules in the list.
+)
+
+// alienKinds are the alien kinds, in the order K steps through them.
+var alienKinds = []AlienKind{AlienAny, AlienFromRight, AlienFromLeft, AlienHunter}
+
+// LevelEditorScene lets a designer lay out levels with the mouse. Clicking places a meteor, and
+// dragging from it sets its velocity; shift-dragging moves it, and right-clicking removes it.
+// Alien schedules are added and changed from the keyboard. T plays the level straight away, and
+// ctrl+S saves the level set to the levels folder, where the options screen (and FindLevelSet)
+// pick it up. Meteors, aliens and the ship are drawn by their own Draw methods, so the level
+// looks just as it will in the game.
+type LevelEditorScene struct {
+	set      *LevelSet      // The level set being edited.
+	level    int            // Which level is being edited.
+	wave     int            // Which of the level's waves is being edited.
+	alien    int            // Which alien schedule is selected.
+	size     MeteorSize     // The size new meteors are placed at.
+	material MeteorMaterial // What new meteors are made of, or "" for the level's mix.
+	dragging int            // The meteor group being aimed or moved, or -1.
+	moving   bool           // Is the meteor being dragged moved, rather than aimed?
+	dragFrom Vector         // Where the drag started. Nothing changes until the mouse moves from here.
+	preview  *GameScene     // A game that's never played, for making meteors and aliens to draw.
+	meteors  []*Meteor      // What the wave's meteors look like, one per group. Only placed ones are shown.
+	aliens   []*Alien       // What the level's aliens look like, one per schedule.
+	stars    []*Star        // The stars for background.
+	dirty    bool           // Are there changes that haven't been saved?
+	leaving  bool           // Was escape pressed with changes that haven't been saved?
+	message  string         // Something to tell the designer, like "SAVED".
+	msgTimer *Timer         // How long until the message goes.
+}
+
+// NewLevelEditorScene opens the level set called name from the levels folder for editing, or
+// starts a new one if there isn't one. The built-in sets can't be changed, so asking for one of
+// those starts a copy of it under a new name.
+func NewLevelEditorScene(name string) (*LevelEditorScene, error) {
+	if name == "" || strings.ContainsAny(name, `/\.`) {
+		return nil, fmt.Errorf("%q can't be used as a level set name", name)
+	}
+
+	e := &LevelEditorScene{
+		size:     MeteorLarge,
+		dragging: -1,
+		stars:    GenerateStars(starCount(), menuRand),
+		msgTimer: NewTimer(editorMessageTime),
+	}
+
+	if s, ok := BuiltInLevelSet(name); ok {
+		e.set = s.clone()
+		e.set.Name = name + "-custom"
+		e.say(fmt.Sprintf("%s IS BUILT IN, SO THIS IS A COPY CALLED %s", strings.ToUpper(name), strings.ToUpper(e.set.Name)))
+	} else if s, err := FindLevelSet(name); err == nil {
+		e.set = s.clone()
+	} else {
+		e.set = &LevelSet{
+			Version:         levelSetVersion,
+			Name:            name,
+			Levels:          []Level{{Waves: []Wave{{}}}},
+			MeteorsPerLevel: 2,
+		}
+		e.say("NEW LEVEL SET " + strings.ToUpper(name))
+	}
+
+	e.preview = newGameScene(editorPreviewSeed, settings.Difficulty, classicLevels(), Silence{})
+	e.refresh()
+	return e, nil
+}
+
+// clone returns a copy of s that can be changed without changing s.
+func (s *LevelSet) clone() *LevelSet {
+	c := *s
+	c.Levels = slices.Clone(s.Levels)
+	for i := range c.Levels {
+		c.Levels[i] = c.Levels[i].clone()
+	}
+	return &c
+}
+
+// Update handles the mouse and keyboard. It's called once per tick.
+func (e *LevelEditorScene) Update(state *State) error {
+	// The designer needs the cursor, even when the game hides it.
+	ebiten.SetCursorMode(ebiten.CursorModeVisible)
+
+	e.msgTimer.Update()
+	if e.msgTimer.IsReady() {
+		e.message = ""
+	}
+
+	ctrl := ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyMeta)
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		if e.dirty && !e.leaving {
+			e.leaving = true
+			e.say("UNSAVED CHANGES! ESCAPE AGAIN TO LEAVE ANYWAY")
+			return nil
+		}
+		useCursor()
+		state.SceneManager.GoToScene(NewTitleScene(0))
+		return nil
+	case ctrl && inpututil.IsKeyJustPressed(ebiten.KeyS):
+		e.save()
+	case inpututil.IsKeyJustPressed(ebiten.KeyT):
+		e.testPlay(state)
+		return nil
+	}
+
+	e.updateMeteors()
+	e.updateWavesAndLevels()
+	e.updateAliens()
+	return nil
+}
+
+// updateMeteors places, aims, moves and removes meteors with the mouse.
+func (e *LevelEditorScene) updateMeteors() {
+	x, y := ebiten.CursorPosition()
+	cursor := Vector{X: float64(x), Y: float64(y)}
+	groups := e.currentWave().Meteors
+
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		switch e.size {
+		case MeteorLarge:
+			e.size = MeteorMedium
+		case MeteorMedium:
+			e.size = MeteorSmall
+		default:
+			e.size = MeteorLarge
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		materials := []MeteorMaterial{"", MaterialRock, MaterialIron, MaterialIce, MaterialExplosive}
+		e.material = materials[(slices.Index(materials, e.material)+1)%len(materials)]
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		if i := e.meteorAt(cursor); i >= 0 {
+			e.currentWave().Meteors = slices.Delete(groups, i, i+1)
+			e.changed()
+		}
+		return
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		e.dragging = e.meteorAt(cursor)
+		e.moving = ebiten.IsKeyPressed(ebiten.KeyShift)
+		e.dragFrom = cursor
+		if e.dragging < 0 {
+			// Place a new meteor, centred on the cursor, and aim it as the mouse is dragged.
+			e.currentWave().Meteors = append(groups, MeteorGroup{Count: 1, Size: e.size, Material: e.material, Velocity: &Vector{}})
+			e.dragging = len(groups)
+			e.moving = false
+			e.refresh()
+			e.placeMeteor(e.dragging, cursor)
+		}
+	}
+
+	// Just clicking a meteor shouldn't stop it dead.
+	if e.dragging >= 0 && ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) && cursor != e.dragFrom {
+		if e.moving {
+			e.placeMeteor(e.dragging, cursor)
+		} else {
+			e.aimMeteor(e.dragging, cursor)
+		}
+	}
+
+	if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) {
+		e.dragging = -1
+	}
+}
+
+// placeMeteor puts the meteor of group i centred on pos.
+func (e *LevelEditorScene) placeMeteor(i int, pos Vector) {
+	bounds := e.meteors[i].sprite.Bounds()
+	at := Vector{
+		X: min(max(pos.X-float64(bounds.Dx())/2, 0), ScreenWidth),
+		Y: min(max(pos.Y-float64(bounds.Dy())/2, 0), ScreenHeight),
+	}
+	e.currentWave().Meteors[i].At = &at
+	e.meteors[i].position = at
+	e.changed()
+}
+
+// aimMeteor points the meteor of group i at target, with the arrow's length setting its speed.
+func (e *LevelEditorScene) aimMeteor(i int, target Vector) {
+	centre := e.meteorCentre(i)
+	e.currentWave().Meteors[i].Velocity = &Vector{
+		X: (target.X - centre.X) / editorArrowScale,
+		Y: (target.Y - centre.Y) / editorArrowScale,
+	}
+	e.changed()
+}
+
+// meteorAt returns the group of the placed meteor under pos, or -1 if there isn't one.
+func (e *LevelEditorScene) meteorAt(pos Vector) int {
+	// Check the ones drawn last (on top) first.
+	for i := len(e.meteors) - 1; i >= 0; i-- {
+		m := e.meteors[i]
+		if e.currentWave().Meteors[i].At == nil {
+			continue
+		}
+		c := e.meteorCentre(i)
+		r := float64(m.sprite.Bounds().Dx()) / 2
+		if math.Hypot(pos.X-c.X, pos.Y-c.Y) <= r {
+			return i
+		}
+	}
+	return -1
+}
+
+// meteorCentre returns the middle of the placed meteor of group i.
+func (e *LevelEditorScene) meteorCentre(i int) Vector {
+	m := e.meteors[i]
+	bounds := m.sprite.Bounds()
+	return Vector{
+		X: m.position.X + float64(bounds.Dx())/2,
+		Y: m.position.Y + float64(bounds.Dy())/2,
+	}
+}
+
+// updateWavesAndLevels moves between waves and levels, and adds new ones.
+func (e *LevelEditorScene) updateWavesAndLevels() {
+	l := e.currentLevel()
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyBracketLeft):
+		e.wave = max(e.wave-1, 0)
+	case inpututil.IsKeyJustPressed(ebiten.KeyBracketRight):
+		e.wave = min(e.wave+1, len(l.Waves)-1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyW):
+		l.Waves = slices.Insert(l.Waves, e.wave+1, Wave{})
+		e.wave++
+		e.changed()
+	case inpututil.IsKeyJustPressed(ebiten.KeyPageUp):
+		e.level = max(e.level-1, 0)
+		e.wave = 0
+		e.alien = 0
+	case inpututil.IsKeyJustPressed(ebiten.KeyPageDown):
+		e.level = min(e.level+1, len(e.set.Levels)-1)
+		e.wave = 0
+		e.alien = 0
+	case inpututil.IsKeyJustPressed(ebiten.KeyN):
+		e.set.Levels = slices.Insert(e.set.Levels, e.level+1, Level{Waves: []Wave{{}}})
+		e.level++
+		e.wave = 0
+		e.alien = 0
+		e.changed()
+	default:
+		return
+	}
+
+	e.refresh()
+}
+
+// updateAliens adds, changes and removes the level's alien schedules.
+func (e *LevelEditorScene) updateAliens() {
+	l := e.currentLevel()
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyA) {
+		l.Aliens = append(l.Aliens, AlienSchedule{Kind: AlienAny, Chance: 50})
+		e.alien = len(l.Aliens) - 1
+		e.changed()
+		return
+	}
+	if len(l.Aliens) == 0 {
+		return
+	}
+
+	a := &l.Aliens[e.alien]
+	every := a.Every
+	if every == 0 {
+		every = tuning.Aliens.SpawnTime
+	}
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyTab):
+		e.alien = (e.alien + 1) % len(l.Aliens)
+		return
+	case inpututil.IsKeyJustPressed(ebiten.KeyK):
+		i := slices.Index(alienKinds, a.Kind)
+		a.Kind = alienKinds[(i+1)%len(alienKinds)]
+	case inpututil.IsKeyJustPressed(ebiten.KeyB):
+		i := slices.Index(alienBehaviours, cmp.Or(a.Behaviour, BehaviourCruise))
+		a.Behaviour = alienBehaviours[(i+1)%len(alienBehaviours)]
+	case inpututil.IsKeyJustPressed(ebiten.KeyF):
+		// Step through the formations, and back to single aliens after the last.
+		names := append([]string{""}, formationNames()...)
+		i := slices.Index(names, a.Formation)
+		a.Formation = names[(i+1)%len(names)]
+	case inpututil.IsKeyJustPressed(ebiten.KeyMinus):
+		a.Chance = max(a.Chance-editorChanceStep, 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEqual):
+		a.Chance = min(a.Chance+editorChanceStep, 100)
+	case inpututil.IsKeyJustPressed(ebiten.KeyComma):
+		a.Every = Duration(max(time.Duration(every)-editorEveryStep, editorEveryStep))
+	case inpututil.IsKeyJustPressed(ebiten.KeyPeriod):
+		a.Every = Duration(time.Duration(every) + editorEveryStep)
+	case inpututil.IsKeyJustPressed(ebiten.KeyX):
+		l.Aliens = slices.Delete(l.Aliens, e.alien, e.alien+1)
+		e.alien = max(min(e.alien, len(l.Aliens)-1), 0)
+	default:
+		return
+	}
+
+	e.changed()
+}
+
+// currentLevel returns the level being edited.
+func (e *LevelEditorScene) currentLevel() *Level {
+	return &e.set.Levels[e.level]
+}
+
+// currentWave returns the wave being edited.
+func (e *LevelEditorScene) currentWave() *Wave {
+	return &e.currentLevel().Waves[e.wave]
+}
+
+// changed notes that there's something to save, and updates what's shown.
+func (e *LevelEditorScene) changed() {
+	e.dirty = true
+	e.leaving = false
+	e.refresh()
+}
+
+// refresh makes the meteors and aliens that show what the level looks like. The sprites come from
+// streams that start from the same seed every time, so nothing changes its looks while being
+// edited.
+func (e *LevelEditorScene) refresh() {
+	rng := newStream(editorPreviewSeed, "editor")
+	e.preview.random = NewRandomStreams(editorPreviewSeed) // NewAlien draws from the preview's.
+
+	e.meteors = e.meteors[:0]
+	for i, g := range e.currentWave().Meteors {
+		m := newMeteor(cmp.Or(g.Size, MeteorLarge), 0, EdgeAny, e.preview, i, rng)
+		m.material = cmp.Or(g.Material, MaterialRock)
+		if g.At != nil {
+			m.position = *g.At
+		}
+		e.meteors = append(e.meteors, m)
+	}
+
+	e.aliens = e.aliens[:0]
+	for i, s := range e.currentLevel().Aliens {
+		a := NewAlien(0, s.Kind, s.Behaviour, e.preview)
+		a.position = Vector{X: editorAlienPanelX, Y: 140 + float64(i)*editorAlienRowSize}
+		e.aliens = append(e.aliens, a)
+	}
+}
+
+// say shows msg for a few seconds.
+func (e *LevelEditorScene) say(msg string) {
+	e.message = msg
+	e.msgTimer.Reset()
+}
+
+// check returns what's wrong with the level set, if anything. Waves with nothing in them are left
+// out of the check (and the file), so the designer can add one before filling it.
+func (e *LevelEditorScene) check(s *LevelSet) error {
+	for i := range s.Levels {
+		s.Levels[i].Waves = slices.DeleteFunc(slices.Clone(s.Levels[i].Waves), func(w Wave) bool {
+			return len(w.Meteors) == 0
+		})
+	}
+	return s.Validate()
+}
+
+// save writes the level set to the levels folder.
+func (e *LevelEditorScene) save() {
+	s := e.set.clone()
+	if err := e.check(s); err != nil {
+		e.say("CAN'T SAVE: " + strings.ToUpper(firstLine(err)))
+		return
+	}
+
+	dir, err := levelsDir()
+	if err == nil {
+		var contents []byte
+		contents, err = json.MarshalIndent(s, "", "  ")
+		if err == nil {
+			err = atomicfile.WriteFile(filepath.Join(dir, e.set.Name+".json"), contents, 0640)
+		}
+	}
+	if err != nil {
+		log.Println("Error saving levels:", err)
+		e.say("COULDN'T SAVE, SEE THE LOG")
+		return
+	}
+
+	e.dirty = false
+	e.leaving = false
+	e.say("SAVED " + strings.ToUpper(e.set.Name))
+}
+
+// testPlay plays the level being edited, on its own, coming back here when it's cleared, the game
+// is over, or the player presses pause.
+func (e *LevelEditorScene) testPlay(state *State) {
+	s := e.set.clone()
+	s.Levels = []Level{s.Levels[e.level]}
+	s.MeteorsPerLevel = 0
+	if err := e.check(s); err != nil {
+		e.say("CAN'T PLAY: " + strings.ToUpper(firstLine(err)))
+		return
+	}
+
+	useCursor()
+	g := newGameScene(NewSeed(), settings.Difficulty, s, defaultSpeakers())
+	g.editor = e
+	state.SceneManager.GoToScene(g)
+}
+
+// firstLine returns the first line of err, since joined errors come one per line.
+func firstLine(err error) string {
+	line, _, _ := strings.Cut(err.Error(), "\n")
+	return line
+}
+
+// Draw draws the wave being edited, the ship for scale, and what the keys do. It's called once
+// per frame.
+func (e *LevelEditorScene) Draw(screen *ebiten.Image) {
+	for _, s := range e.stars {
+		s.Draw(screen)
+	}
+
+	e.preview.player.Draw(screen)
+
+	// Draw the placed meteors, with arrows for where they're heading.
+	for i, m := range e.meteors {
+		g := e.currentWave().Meteors[i]
+		if g.At == nil {
+			continue
+		}
+		m.Draw(screen)
+
+		v := g.Velocity
+		if v == nil {
+			continue
+		}
+		c := e.meteorCentre(i)
+		tip := Vector{X: c.X + v.X*editorArrowScale, Y: c.Y + v.Y*editorArrowScale}
+		vector.StrokeLine(screen, float32(c.X), float32(c.Y), float32(tip.X), float32(tip.Y), 2, color.RGBA{G: 0xc0, B: 0xff, A: 0xff}, true)
+		vector.DrawFilledCircle(screen, float32(tip.X), float32(tip.Y), 4, color.RGBA{G: 0xc0, B: 0xff, A: 0xff}, true)
+	}
+
+	// Draw the alien schedules down the right.
+	for i, a := range e.aliens {
+		a.Draw(screen)
+
+		s := e.currentLevel().Aliens[i]
+		every := s.Every
+		if every == 0 {
+			every = tuning.Aliens.SpawnTime
+		}
+		label := fmt.Sprintf("%s %s  %d%% EVERY %v", strings.ToUpper(string(s.Kind)), strings.ToUpper(string(cmp.Or(s.Behaviour, BehaviourCruise))), s.Chance, time.Duration(every))
+		if s.Formation != "" {
+			label = fmt.Sprintf("FORMATION %s  %d%% EVERY %v", strings.ToUpper(s.Formation), s.Chance, time.Duration(every))
+		}
+		if i == e.alien {
+			label = "> " + label
+		}
+		drawEditorText(screen, label, a.position.X-50, a.position.Y-8, 16, text.AlignEnd)
+	}
+	drawEditorText(screen, "ALIENS", editorAlienPanelX, 80, 16, text.AlignEnd)
+
+	// Draw where we are, and anything that isn't placed with the mouse.
+	y := 20.0
+	lines := []string{
+		fmt.Sprintf("LEVEL SET %s", strings.ToUpper(e.set.Name)),
+		fmt.Sprintf("LEVEL %d OF %d   WAVE %d OF %d", e.level+1, len(e.set.Levels), e.wave+1, len(e.currentLevel().Waves)),
+		fmt.Sprintf("NEW METEORS ARE %s %s", strings.ToUpper(string(e.size)), strings.ToUpper(string(cmp.Or(e.material, "from the mix")))),
+	}
+	for _, g := range e.currentWave().Meteors {
+		if g.At == nil {
+			lines = append(lines, fmt.Sprintf("+ %d %s %s FROM %s EDGE", g.Count, strings.ToUpper(string(cmp.Or(g.Material, "mixed"))), strings.ToUpper(string(cmp.Or(g.Size, MeteorLarge))), strings.ToUpper(string(cmp.Or(g.Edge, EdgeAny)))))
+		}
+	}
+	for _, line := range lines {
+		drawEditorText(screen, line, editorPanelX, y, 16, text.AlignStart)
+		y += 26
+	}
+
+	help := []string{
+		"CLICK: PLACE METEOR, DRAG TO AIM   SHIFT-DRAG: MOVE   RIGHT CLICK: REMOVE   SPACE: SIZE   M: MATERIAL",
+		"[ ]: WAVE   W: NEW WAVE   PGUP/PGDN: LEVEL   N: NEW LEVEL",
+		"A: NEW ALIEN   TAB: NEXT ALIEN   K: KIND   B: BEHAVIOUR   F: FORMATION   - =: CHANCE   , .: HOW OFTEN   X: REMOVE ALIEN",
+		"T: TEST PLAY   CTRL+S: SAVE   ESC: LEAVE",
+	}
+	for i, line := range help {
+		drawEditorText(screen, line, ScreenWidth/2, ScreenHeight-110+float64(i)*24, 14, text.AlignCenter)
+	}
+
+	if e.message != "" {
+		drawEditorText(screen, e.message, ScreenWidth/2, ScreenHeight/2-200, 24, text.AlignCenter)
+	}
+}
+
+// drawEditorText draws one line of the editor's text at x, y, lined up by align.
+func drawEditorText(screen *ebiten.Image, s string, x, y float64, size float64, align text.Align) {
+	op := &text.DrawOptions{
+		LayoutOptions: text.LayoutOptions{
+			PrimaryAlign: align,
+		},
+	}
+	op.ColorScale.ScaleWithColor(color.White)
+	op.GeoM.Translate(x, y)
+	text.Draw(screen, s, &text.GoTextFace{
+		Source: assets.ScoreFont,
+		Size:   size,
+	}, op)
+}
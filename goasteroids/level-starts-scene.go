@@ -4,17 +4,16 @@ import (
 	"asteroids/assets"
 	"fmt"
 	"image/color"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 )
 
-// LevelStartsScene is the type for our title scene. It holds the game, a timer, and a slice of stars.
+// LevelStartsScene is the type for our title scene. It holds the game and a slice of stars.
 type LevelStartsScene struct {
-	game           *GameScene
-	nextLevelTimer *Timer
-	stars          []*Star
+	game  *GameScene
+	stars []*Star
 }
 
 // Draw puts all the elements on the screen. It's called once per frame.
@@ -37,32 +36,44 @@ func (l *LevelStartsScene) Draw(screen *ebiten.Image) {
 		Source: assets.TitleFont,
 		Size:   48,
 	}, op)
-}
 
-// Update updates screen elements. It's called once per tick.
-func (l *LevelStartsScene) Update(state *State) error {
-	// Update timer
-	l.nextLevelTimer.Update()
-	if l.nextLevelTimer.IsReady() {
-		l.game.meteorsForLevel += 2
-		l.game.meteorCount = 0
-		for k, v := range l.game.lasers {
-			delete(l.game.lasers, k)
-			l.game.space.Remove(v.laserObj)
+	// Draw the level's name, if it has one.
+	if name := l.game.LevelName(l.game.currentLevel); name != "" {
+		op = &text.DrawOptions{
+			LayoutOptions: text.LayoutOptions{
+				PrimaryAlign: text.AlignCenter,
+			},
 		}
-		state.SceneManager.GoToScene(l.game)
+		op.ColorScale.ScaleWithColor(color.White)
+		op.GeoM.Translate(ScreenWidth/2, ScreenHeight/2+70)
+		text.Draw(screen, strings.ToUpper(name), &text.GoTextFace{
+			Source: assets.ScoreFont,
+			Size:   24,
+		}, op)
 	}
 
-	// Check to see  if the space bar is pressed. If it is, go to the next scene.
-	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-		l.game.meteorsForLevel += 5
-		l.game.meteorCount = 0
-		for k, v := range l.game.lasers {
-			delete(l.game.lasers, k)
-			l.game.space.Remove(v.laserObj)
+	// Draw the bonuses the player earned on the last level, if they did.
+	for i, b := range l.game.bonuses {
+		op = &text.DrawOptions{
+			LayoutOptions: text.LayoutOptions{
+				PrimaryAlign: text.AlignCenter,
+			},
 		}
+		op.ColorScale.ScaleWithColor(color.White)
+		op.GeoM.Translate(ScreenWidth/2, ScreenHeight/2-100-float64(i)*30)
+		text.Draw(screen, fmt.Sprintf("BONUS  %s  +%d", b.Label(), b.Points), &text.GoTextFace{
+			Source: assets.ScoreFont,
+			Size:   16,
+		}, op)
+	}
+}
+
+// Update updates screen elements. It's called once per tick. The game itself decides when the
+// next level starts (firing starts it early), so we keep stepping it until it does.
+func (l *LevelStartsScene) Update(state *State) error {
+	l.game.Step(state.Input.Frame())
+	if l.game.phase == PhasePlaying {
 		state.SceneManager.GoToScene(l.game)
-		return nil
 	}
 
 	return nil
@@ -0,0 +1,598 @@
+package goasteroids
+
+import (
+	"asteroids/assets"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const (
+	levelSetVersion = 1         // Bump this when the layout of level files changes.
+	ClassicLevels   = "classic" // The level set games are played with unless the player picks another.
+)
+
+// MeteorSize is how big the meteors in a group are.
+type MeteorSize string
+
+const (
+	MeteorLarge  MeteorSize = "large"
+	MeteorMedium MeteorSize = "medium"
+	MeteorSmall  MeteorSize = "small"
+)
+
+// MeteorMaterial is what the meteors in a group are made of.
+type MeteorMaterial string
+
+const (
+	MaterialRock      MeteorMaterial = "rock"      // One hit, and it breaks in two.
+	MaterialIron      MeteorMaterial = "iron"      // Takes several hits before it breaks in two.
+	MaterialIce       MeteorMaterial = "ice"       // Shatters into fast, tiny shards.
+	MaterialExplosive MeteorMaterial = "explosive" // Blows up, taking anything close by with it.
+)
+
+// Edge is the side of the screen meteors come in from.
+type Edge string
+
+const (
+	EdgeAny    Edge = "any"
+	EdgeLeft   Edge = "left"
+	EdgeRight  Edge = "right"
+	EdgeTop    Edge = "top"
+	EdgeBottom Edge = "bottom"
+)
+
+// AlienKind is which sort of alien a schedule sends.
+type AlienKind string
+
+const (
+	AlienAny       AlienKind = "any"        // Any of the others, picked at random.
+	AlienFromRight AlienKind = "from-right" // Flies in from the right and shoots in random directions.
+	AlienFromLeft  AlienKind = "from-left"  // Flies in from the left and shoots in random directions.
+	AlienHunter    AlienKind = "hunter"     // Flies in from anywhere and shoots at the player.
+)
+
+// BonusKind is what the player has to do to earn a level's bonus.
+type BonusKind string
+
+const (
+	BonusNoDeaths  BonusKind = "no-deaths"  // Clear the level without losing a life.
+	BonusNoShields BonusKind = "no-shields" // Clear the level without raising a shield.
+	BonusTime      BonusKind = "time"       // Clear the level within a time limit.
+
+	// BonusBoss is for destroying a boss. Levels can't ask for it; it's earned on boss levels.
+	BonusBoss BonusKind = "boss"
+)
+
+// LevelSet is a run of levels, played one after the other. Once the last one is cleared it's
+// played again, with more meteors each time, for as long as the player lasts.
+type LevelSet struct {
+	Version         int     `json:"version"`
+	Name            string  `json:"name"`            // Shown on the options screen, and recorded in replays.
+	Levels          []Level `json:"levels"`          // The levels, first first.
+	MeteorsPerLevel int     `json:"meteorsPerLevel"` // How many more meteors each level past the last one gets.
+	SkipBonus       int     `json:"skipBonus"`       // Firing to skip the pause between levels adds this many meteors to every level after.
+}
+
+// Level is one level: waves of meteors, the aliens that turn up while they're being cleared, and
+// how it sounds. Anything left out is taken from the tuning.
+type Level struct {
+	Name   string          `json:"name,omitempty"`   // Shown under the level number, if there is one.
+	Waves  []Wave          `json:"waves"`            // Played in order. Each starts once the one before is cleared.
+	Speed  MeteorSpeed     `json:"speed"`            // How fast the level's meteors fly.
+	Aliens []AlienSchedule `json:"aliens,omitempty"` // No aliens if there are none.
+	Beat   Beat            `json:"beat"`             // The heartbeat's tempo.
+	Bonus  *Bonus          `json:"bonus,omitempty"`  // Points for clearing the level in style.
+
+	// Materials is the mix of materials for meteors whose group doesn't say, as weights: {"rock": 3,
+	// "ice": 1} makes one in four ice. They're all rock if it's left out.
+	Materials map[MeteorMaterial]int `json:"materials,omitempty"`
+}
+
+// Wave is a batch of meteors. Groups are sent in order.
+type Wave struct {
+	Delay     Duration      `json:"delay,omitempty"`     // The pause before the first meteor.
+	SpawnTime Duration      `json:"spawnTime,omitempty"` // The wait between meteors.
+	Meteors   []MeteorGroup `json:"meteors"`
+}
+
+// MeteorGroup is some meteors of one size, all coming from the same side, or all placed at the
+// same spot (which is what the level editor does).
+type MeteorGroup struct {
+	Count    int            `json:"count"`
+	Size     MeteorSize     `json:"size,omitempty"`     // Large if it's left out.
+	Material MeteorMaterial `json:"material,omitempty"` // Picked from the level's mix if it's left out.
+	Edge     Edge           `json:"edge,omitempty"`     // Any side if it's left out.
+	At       *Vector        `json:"at,omitempty"`       // Where the meteors appear, instead of coming in from an edge.
+	Velocity *Vector        `json:"velocity,omitempty"` // How placed meteors move, in pixels per tick. They sit still without one.
+}
+
+// MeteorSpeed is the speed curve for a level's meteors: they start at Base, and get SpeedUp
+// faster every so often, up to Max. The difficulty scales Base and Max.
+type MeteorSpeed struct {
+	Base    float64  `json:"base,omitempty"`
+	SpeedUp float64  `json:"speedUp,omitempty"`
+	Every   Duration `json:"every,omitempty"`
+	Max     float64  `json:"max,omitempty"` // 0 means there's no limit.
+}
+
+// AlienSchedule sends aliens of one kind, flying the way Behaviour says. Every so often there's a
+// Chance in 100 of one turning up, as long as there are fewer than Max on the screen. A schedule
+// with a Formation sends a whole formation instead, as long as there are fewer than Max of them
+// flying, and its Kind and Behaviour don't count.
+type AlienSchedule struct {
+	Kind      AlienKind      `json:"kind,omitempty"`
+	Behaviour AlienBehaviour `json:"behaviour,omitempty"` // Cruise if it's left out.
+	Formation string         `json:"formation,omitempty"` // The name of a formation in assets/data/formations.json.
+	Every     Duration       `json:"every,omitempty"`
+	Chance    int            `json:"chance"`
+	Max       int            `json:"max,omitempty"` // 1 if it's left out.
+}
+
+// Beat is the heartbeat's tempo: it starts with Start between beats, and gets SpeedUp quicker
+// every beat until it's down to Min.
+type Beat struct {
+	Start   Duration `json:"start,omitempty"`
+	Min     Duration `json:"min,omitempty"`
+	SpeedUp Duration `json:"speedUp,omitempty"`
+}
+
+// Bonus is a bonus objective for a level.
+type Bonus struct {
+	Kind   BonusKind `json:"kind"`
+	Within Duration  `json:"within,omitempty"` // The time limit, for "time" bonuses.
+	Points int       `json:"points"`
+}
+
+// levelStats is what the player got up to on the level being played, for bonus objectives.
+type levelStats struct {
+	ticks   int // How long they've been playing it.
+	deaths  int // Lives lost.
+	shields int // Shields raised.
+}
+
+var builtInLevelSets = mustBuiltInLevelSets() // The level sets that ship with the game.
+
+// mustBuiltInLevelSets reads the level sets in assets/data/levels. A broken one is a bug, so it
+// panics, like a missing asset does.
+func mustBuiltInLevelSets() []*LevelSet {
+	var sets []*LevelSet
+	for _, data := range assets.LevelData {
+		s, err := parseLevelSet(data)
+		if err != nil {
+			panic(fmt.Sprintf("built-in levels: %v", err))
+		}
+		sets = append(sets, s)
+	}
+
+	// Classic goes first, since it's the default.
+	slices.SortStableFunc(sets, func(a, b *LevelSet) int {
+		switch {
+		case a.Name == b.Name:
+			return 0
+		case a.Name == ClassicLevels:
+			return -1
+		case b.Name == ClassicLevels:
+			return 1
+		}
+		return 0
+	})
+	return sets
+}
+
+// classicLevels returns the classic level set, where every level is the one before with more
+// meteors.
+func classicLevels() *LevelSet {
+	s, _ := BuiltInLevelSet(ClassicLevels)
+	return s
+}
+
+// BuiltInLevelSet returns the level set called name that ships with the game, if there is one.
+func BuiltInLevelSet(name string) (*LevelSet, bool) {
+	for _, s := range builtInLevelSets {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// levelsDir returns the folder the player's own level sets live in.
+func levelsDir() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "levels"), nil
+}
+
+// LoadLevelSets returns every level set there is: the built-in ones, then any in the levels
+// folder of the data directory. Files that can't be read are left out, and the error says why.
+func LoadLevelSets() ([]*LevelSet, error) {
+	sets := slices.Clone(builtInLevelSets)
+
+	dir, err := levelsDir()
+	if err != nil {
+		return sets, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return sets, err
+	}
+
+	var errs []error
+	for _, path := range paths {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		s, err := parseLevelSet(contents)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("reading %s: %w", path, err))
+			continue
+		}
+		if slices.ContainsFunc(sets, func(o *LevelSet) bool { return o.Name == s.Name }) {
+			errs = append(errs, fmt.Errorf("reading %s: there's already a level set called %q", path, s.Name))
+			continue
+		}
+		sets = append(sets, s)
+	}
+	return sets, errors.Join(errs...)
+}
+
+// FindLevelSet returns the level set called name, built-in or not. "" means the classic set.
+func FindLevelSet(name string) (*LevelSet, error) {
+	if name == "" {
+		name = ClassicLevels
+	}
+	if s, ok := BuiltInLevelSet(name); ok {
+		return s, nil
+	}
+
+	sets, err := LoadLevelSets()
+	for _, s := range sets {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("no level set called %q: %w", name, err)
+	}
+	return nil, fmt.Errorf("no level set called %q", name)
+}
+
+// parseLevelSet reads and checks a level file.
+func parseLevelSet(data []byte) (*LevelSet, error) {
+	var s LevelSet
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields() // A typo shouldn't silently leave a value at its default.
+	if err := d.Decode(&s); err != nil {
+		return nil, err
+	}
+	if s.Version != levelSetVersion {
+		return nil, fmt.Errorf("unsupported version %d", s.Version)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Validate checks that every level makes sense, and says what doesn't if it doesn't.
+func (s *LevelSet) Validate() error {
+	var errs []error
+	if s.Name == "" {
+		errs = append(errs, errors.New("name is missing"))
+	}
+	if len(s.Levels) == 0 {
+		errs = append(errs, errors.New("there must be at least one level"))
+	}
+	if s.MeteorsPerLevel < 0 {
+		errs = append(errs, fmt.Errorf("meteorsPerLevel can't be negative, not %d", s.MeteorsPerLevel))
+	}
+	if s.SkipBonus < 0 {
+		errs = append(errs, fmt.Errorf("skipBonus can't be negative, not %d", s.SkipBonus))
+	}
+	for i, l := range s.Levels {
+		errs = append(errs, l.validate(fmt.Sprintf("levels[%d]", i))...)
+	}
+	return errors.Join(errs...)
+}
+
+// validate checks level l, which is called name in the errors.
+func (l Level) validate(name string) []error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(name+"."+format, args...))
+	}
+	notNegative := func(field string, v float64) {
+		if v < 0 {
+			fail("%s can't be negative, not %v", field, v)
+		}
+	}
+	noneOrATick := func(field string, d Duration) {
+		if d != 0 && time.Duration(d) < time.Second/TicksPerSecond {
+			fail("%s must be at least one tick (%v), not %v", field, time.Second/TicksPerSecond, time.Duration(d))
+		}
+	}
+
+	if len(l.Waves) == 0 {
+		fail("waves: there must be at least one wave")
+	}
+	for i, w := range l.Waves {
+		notNegative(fmt.Sprintf("waves[%d].delay", i), float64(w.Delay))
+		noneOrATick(fmt.Sprintf("waves[%d].spawnTime", i), w.SpawnTime)
+		if len(w.Meteors) == 0 {
+			fail("waves[%d].meteors: there must be at least one group of meteors", i)
+		}
+		for j, m := range w.Meteors {
+			if m.Count < 1 {
+				fail("waves[%d].meteors[%d].count must be at least 1, not %d", i, j, m.Count)
+			}
+			if !slices.Contains([]MeteorSize{"", MeteorLarge, MeteorMedium, MeteorSmall}, m.Size) {
+				fail("waves[%d].meteors[%d].size: unknown size %q", i, j, m.Size)
+			}
+			if !slices.Contains([]MeteorMaterial{"", MaterialRock, MaterialIron, MaterialIce, MaterialExplosive}, m.Material) {
+				fail("waves[%d].meteors[%d].material: unknown material %q", i, j, m.Material)
+			}
+			if !slices.Contains([]Edge{"", EdgeAny, EdgeLeft, EdgeRight, EdgeTop, EdgeBottom}, m.Edge) {
+				fail("waves[%d].meteors[%d].edge: unknown edge %q", i, j, m.Edge)
+			}
+			if m.At != nil && (m.At.X < 0 || m.At.X > ScreenWidth || m.At.Y < 0 || m.At.Y > ScreenHeight) {
+				fail("waves[%d].meteors[%d].at (%v, %v) is off the screen", i, j, m.At.X, m.At.Y)
+			}
+			if m.Velocity != nil && m.At == nil {
+				fail("waves[%d].meteors[%d].velocity is only for meteors placed with at", i, j)
+			}
+		}
+	}
+
+	total := 0
+	for _, material := range slices.Sorted(maps.Keys(l.Materials)) {
+		if !slices.Contains([]MeteorMaterial{MaterialRock, MaterialIron, MaterialIce, MaterialExplosive}, material) {
+			fail("materials: unknown material %q", material)
+		}
+		notNegative(fmt.Sprintf("materials.%s", material), float64(l.Materials[material]))
+		total += l.Materials[material]
+	}
+	if len(l.Materials) > 0 && total <= 0 {
+		fail("materials: at least one material needs a weight above 0")
+	}
+
+	notNegative("speed.base", l.Speed.Base)
+	notNegative("speed.speedUp", l.Speed.SpeedUp)
+	noneOrATick("speed.every", l.Speed.Every)
+	notNegative("speed.max", l.Speed.Max)
+
+	for i, a := range l.Aliens {
+		if !slices.Contains([]AlienKind{"", AlienAny, AlienFromRight, AlienFromLeft, AlienHunter}, a.Kind) {
+			fail("aliens[%d].kind: unknown kind %q", i, a.Kind)
+		}
+		if a.Behaviour != "" && !slices.Contains(alienBehaviours, a.Behaviour) {
+			fail("aliens[%d].behaviour: unknown behaviour %q", i, a.Behaviour)
+		}
+		if _, ok := findFormation(a.Formation); a.Formation != "" && !ok {
+			fail("aliens[%d].formation: unknown formation %q", i, a.Formation)
+		}
+		noneOrATick(fmt.Sprintf("aliens[%d].every", i), a.Every)
+		if a.Chance < 1 || a.Chance > 100 {
+			fail("aliens[%d].chance must be from 1 to 100, not %d", i, a.Chance)
+		}
+		notNegative(fmt.Sprintf("aliens[%d].max", i), float64(a.Max))
+	}
+
+	noneOrATick("beat.start", l.Beat.Start)
+	noneOrATick("beat.min", l.Beat.Min)
+	notNegative("beat.speedUp", float64(l.Beat.SpeedUp))
+	if l.Beat.Start != 0 && l.Beat.Start < l.Beat.Min {
+		fail("beat.start (%v) can't be less than beat.min (%v)", time.Duration(l.Beat.Start), time.Duration(l.Beat.Min))
+	}
+
+	if b := l.Bonus; b != nil {
+		if !slices.Contains([]BonusKind{BonusNoDeaths, BonusNoShields, BonusTime}, b.Kind) {
+			fail("bonus.kind: unknown kind %q", b.Kind)
+		}
+		if b.Kind == BonusTime && time.Duration(b.Within) < time.Second/TicksPerSecond {
+			fail("bonus.within: a time bonus needs a time limit of at least one tick")
+		}
+		if b.Points < 1 {
+			fail("bonus.points must be at least 1, not %d", b.Points)
+		}
+	}
+
+	return errs
+}
+
+// level returns level n (counting from 1) with extra more meteors, and the tuning's numbers filled
+// in for anything it leaves out. Past the last level, the last level is played again with
+// MeteorsPerLevel more meteors each time. More meteors join the first group of the first wave
+// that comes in from an edge, or come in as large meteors from any edge if there isn't one.
+func (s *LevelSet) level(n, extra int, t *Tuning) Level {
+	l := s.Levels[min(n, len(s.Levels))-1].clone()
+	if n > len(s.Levels) {
+		extra += s.MeteorsPerLevel * (n - len(s.Levels))
+	}
+
+	if extra > 0 {
+		first := &l.Waves[0]
+		i := slices.IndexFunc(first.Meteors, func(m MeteorGroup) bool { return m.At == nil })
+		if i < 0 {
+			first.Meteors = append(first.Meteors, MeteorGroup{})
+			i = len(first.Meteors) - 1
+		}
+		first.Meteors[i].Count += extra
+	}
+	return l.withDefaults(t)
+}
+
+// levelName returns the name of level n, if it has one.
+func (s *LevelSet) levelName(n int) string {
+	return s.Levels[min(n, len(s.Levels))-1].Name
+}
+
+// clone returns a copy of l that can be changed without changing l.
+func (l Level) clone() Level {
+	l.Waves = slices.Clone(l.Waves)
+	for i := range l.Waves {
+		l.Waves[i].Meteors = slices.Clone(l.Waves[i].Meteors)
+	}
+	l.Aliens = slices.Clone(l.Aliens)
+	l.Materials = maps.Clone(l.Materials)
+	return l
+}
+
+// withDefaults fills in everything l leaves out from tuning t. It changes l's waves and aliens in
+// place, so l should be a clone.
+func (l Level) withDefaults(t *Tuning) Level {
+	for i := range l.Waves {
+		w := &l.Waves[i]
+		if w.SpawnTime == 0 {
+			w.SpawnTime = t.Meteors.SpawnTime
+		}
+		for j := range w.Meteors {
+			m := &w.Meteors[j]
+			if m.Size == "" {
+				m.Size = MeteorLarge
+			}
+			if m.Edge == "" {
+				m.Edge = EdgeAny
+			}
+		}
+	}
+
+	if l.Speed.Base == 0 {
+		l.Speed.Base = t.Meteors.BaseVelocity
+	}
+	if l.Speed.SpeedUp == 0 {
+		l.Speed.SpeedUp = t.Meteors.SpeedUpAmount
+	}
+	if l.Speed.Every == 0 {
+		l.Speed.Every = t.Meteors.SpeedUpTime
+	}
+
+	for i := range l.Aliens {
+		a := &l.Aliens[i]
+		if a.Kind == "" {
+			a.Kind = AlienAny
+		}
+		if a.Every == 0 {
+			a.Every = t.Aliens.SpawnTime
+		}
+		if a.Max == 0 {
+			a.Max = 1
+		}
+	}
+
+	if l.Beat.Start == 0 {
+		l.Beat.Start = t.Levels.BaseBeatWait
+	}
+	if l.Beat.Min == 0 {
+		l.Beat.Min = t.Levels.MinBeatWait
+	}
+	if l.Beat.SpeedUp == 0 {
+		l.Beat.SpeedUp = t.Levels.BeatSpeedUp
+	}
+
+	return l
+}
+
+// material picks what a meteor is made of from the level's mix, using rng. It's rock if the level
+// doesn't have a mix, and then rng isn't used at all.
+func (l Level) material(rng *rand.Rand) MeteorMaterial {
+	total := 0
+	for _, weight := range l.Materials {
+		total += weight
+	}
+	if total <= 0 {
+		return MaterialRock
+	}
+
+	// Go through the materials in the same order every time, so replays pick the same ones.
+	n := rng.IntN(total)
+	for _, material := range slices.Sorted(maps.Keys(l.Materials)) {
+		n -= l.Materials[material]
+		if n < 0 {
+			return material
+		}
+	}
+	return MaterialRock
+}
+
+// size returns how many meteors the wave sends.
+func (w Wave) size() int {
+	n := 0
+	for _, m := range w.Meteors {
+		n += m.Count
+	}
+	return n
+}
+
+// group returns the group the wave's i'th meteor comes from.
+func (w Wave) group(i int) MeteorGroup {
+	for _, m := range w.Meteors {
+		if i < m.Count {
+			return m
+		}
+		i -= m.Count
+	}
+	return w.Meteors[len(w.Meteors)-1]
+}
+
+// spawnAngle picks the angle around the middle of the screen that something coming in from e
+// starts at: anywhere at all, or within 45° either side of the middle of its side.
+func (e Edge) spawnAngle(rng *rand.Rand) float64 {
+	var middle float64
+	switch e {
+	case EdgeRight:
+		middle = 0
+	case EdgeBottom:
+		middle = math.Pi / 2
+	case EdgeLeft:
+		middle = math.Pi
+	case EdgeTop:
+		middle = 3 * math.Pi / 2
+	default:
+		// 2π is 360°, so this returns an angle between 0° to 360°.
+		return rng.Float64() * 2 * math.Pi
+	}
+	return middle + (rng.Float64()-0.5)*math.Pi/2
+}
+
+// earned reports whether the player met the objective, going by what they did on the level.
+func (b *Bonus) earned(s levelStats) bool {
+	switch b.Kind {
+	case BonusNoDeaths:
+		return s.deaths == 0
+	case BonusNoShields:
+		return s.shields == 0
+	case BonusTime:
+		return time.Duration(s.ticks)*time.Second/TicksPerSecond <= time.Duration(b.Within)
+	}
+	return false
+}
+
+// Label describes the objective, for the screen between levels.
+func (b *Bonus) Label() string {
+	switch b.Kind {
+	case BonusNoDeaths:
+		return "NO LIVES LOST"
+	case BonusNoShields:
+		return "NO SHIELDS USED"
+	case BonusTime:
+		return fmt.Sprintf("CLEARED IN UNDER %v", time.Duration(b.Within))
+	case BonusBoss:
+		return "MOTHERSHIP DESTROYED"
+	}
+	return string(b.Kind)
+}
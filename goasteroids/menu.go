@@ -0,0 +1,88 @@
+package goasteroids
+
+import (
+	"asteroids/assets"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// MenuItem is one choice on a menu.
+type MenuItem struct {
+	Label    string // What the player sees.
+	Disabled bool   // Disabled items are drawn dimmed and can't be chosen.
+}
+
+// Menu is a list of choices, one above the other. Up and down (thrust and reverse, so the arrow
+// keys, d-pad and stick all work) move the highlight, and confirm picks the highlighted item.
+type Menu struct {
+	items    []MenuItem
+	selected int
+}
+
+// NewMenu creates a menu with the first item highlighted.
+func NewMenu(items ...MenuItem) *Menu {
+	return &Menu{items: items}
+}
+
+// Update moves the highlight. If the player picked an item this tick, it returns its index and
+// true.
+func (m *Menu) Update(input *Input) (int, bool) {
+	if input.IsJustPressed(ActionThrust) {
+		m.move(-1)
+	}
+	if input.IsJustPressed(ActionReverse) {
+		m.move(1)
+	}
+
+	if input.IsJustPressed(ActionConfirm) && !m.items[m.selected].Disabled {
+		return m.selected, true
+	}
+	return 0, false
+}
+
+// Selected returns the index of the highlighted item.
+func (m *Menu) Selected() int {
+	return m.selected
+}
+
+// SetLabel changes what item i says, for menus that show a value next to each choice.
+func (m *Menu) SetLabel(i int, label string) {
+	m.items[i].Label = label
+}
+
+// move moves the highlight by step, skipping disabled items and wrapping around at the ends.
+func (m *Menu) move(step int) {
+	for range m.items {
+		m.selected = (m.selected + step + len(m.items)) % len(m.items)
+		if !m.items[m.selected].Disabled {
+			return
+		}
+	}
+}
+
+// Draw draws the menu centred on x, with its first item at y.
+func (m *Menu) Draw(screen *ebiten.Image, x, y float64) {
+	for i, item := range m.items {
+		textToDraw := item.Label
+		if i == m.selected {
+			textToDraw = "> " + textToDraw + " <"
+		}
+
+		op := &text.DrawOptions{
+			LayoutOptions: text.LayoutOptions{
+				PrimaryAlign: text.AlignCenter,
+			},
+		}
+		op.ColorScale.ScaleWithColor(color.White)
+		if item.Disabled {
+			op.ColorScale.ScaleAlpha(0.3)
+		}
+		op.GeoM.Translate(x, y+float64(i)*50)
+		text.Draw(screen, textToDraw, &text.GoTextFace{
+			Source: assets.ScoreFont,
+			Size:   24,
+		}, op)
+	}
+}
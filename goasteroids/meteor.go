@@ -3,21 +3,82 @@ package goasteroids
 import (
 	"asteroids/assets"
 	"math"
-	"math/rand"
+	"math/rand/v2"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/solarlune/resolv"
 )
 
 const (
-	rotationSpeedMin                    = -0.02
-	rotationSpeedMax                    = 0.02
-	numberOfSmallMeteorsFromLargeMeteor = 4
+	rotationSpeedMin = -0.02
+	rotationSpeedMax = 0.02
+	meteorHitFlash   = 6 // Ticks an iron meteor flashes for when it's hit and doesn't break.
 )
 
-// Meteor is the type for all meteors, both big and small.
+// meteorShard is the size of the shards an ice meteor shatters into. Levels can't ask for them.
+const meteorShard MeteorSize = "shard"
+
+// meteorTier is what sets one size of meteor apart from the others.
+type meteorTier struct {
+	sprites    []*ebiten.Image // The looks to pick from.
+	explosion  *ebiten.Image   // What it looks like once it's hit.
+	tag        resolv.Tags     // The size's collision tag.
+	radius     float64         // The collision radius.
+	breaksInto MeteorSize      // What it breaks into when it's hit, or "" if it's the smallest.
+}
+
+// meteorTiers are the sizes of meteor, biggest first. A large meteor breaks into medium ones, which
+// break into small ones. What each is worth, and how many pieces it breaks into, is in the tuning.
+var meteorTiers = map[MeteorSize]meteorTier{
+	MeteorLarge: {
+		sprites:    assets.MeteorSprites,
+		explosion:  assets.ExplosionSprite,
+		tag:        TagLarge,
+		radius:     48,
+		breaksInto: MeteorMedium,
+	},
+	MeteorMedium: {
+		sprites:    assets.MeteorSpritesMedium,
+		explosion:  assets.ExplosionMediumSprite,
+		tag:        TagMedium,
+		radius:     32,
+		breaksInto: MeteorSmall,
+	},
+	MeteorSmall: {
+		sprites:   assets.MeteorSpritesSmall,
+		explosion: assets.ExplosionSmallSprite,
+		tag:       TagSmall,
+		radius:    21,
+	},
+	meteorShard: {
+		sprites:   assets.MeteorSpritesShard,
+		explosion: assets.ExplosionShardSprite,
+		tag:       TagSmall,
+		radius:    10,
+	},
+}
+
+// meteorLook is how a material colours a meteor, and its explosion.
+type meteorLook struct {
+	tint      [3]float32 // Red, green and blue the meteor's scaled by.
+	explosion [3]float32 // Red, green and blue its explosion's scaled by.
+}
+
+// meteorLooks are the looks for each material.
+var meteorLooks = map[MeteorMaterial]meteorLook{
+	MaterialRock:      {tint: [3]float32{1, 1, 1}, explosion: [3]float32{1, 1, 1}},
+	MaterialIron:      {tint: [3]float32{0.6, 0.65, 0.75}, explosion: [3]float32{1.4, 0.9, 0.5}},
+	MaterialIce:       {tint: [3]float32{0.6, 0.9, 1.4}, explosion: [3]float32{0.7, 1, 1.5}},
+	MaterialExplosive: {tint: [3]float32{1.4, 0.55, 0.4}, explosion: [3]float32{1.6, 0.8, 0.3}},
+}
+
+// Meteor is the type for all meteors, whatever their size.
 type Meteor struct {
 	game          *GameScene     // Embed the game so we have access to it.
+	size          MeteorSize     // How big it is.
+	material      MeteorMaterial // What it's made of.
+	damage        int            // How many times it's been hit.
+	hitFlash      int            // Ticks left of the flash from being hit.
 	position      Vector         // Where is the meteor.
 	rotation      float64        // The rotation for the meteor.
 	movement      Vector         // What direction is it going.
@@ -27,16 +88,35 @@ type Meteor struct {
 	meteorObj     *resolv.Circle // The collision object.
 }
 
-// NewMeteor is a factory method which creates a new large meteor.
-func NewMeteor(baseVelocity float64, g *GameScene, index int) *Meteor {
+// NewMeteor is a factory method which creates a new large meteor, coming in from edge. Its
+// position, speed and looks are drawn from rng.
+func NewMeteor(baseVelocity float64, edge Edge, g *GameScene, index int, rng *rand.Rand) *Meteor {
+	return newMeteor(MeteorLarge, baseVelocity, edge, g, index, rng)
+}
+
+// NewMediumMeteor is a factory method which creates a new medium meteor, coming in from edge.
+func NewMediumMeteor(baseVelocity float64, edge Edge, g *GameScene, index int, rng *rand.Rand) *Meteor {
+	return newMeteor(MeteorMedium, baseVelocity, edge, g, index, rng)
+}
+
+// NewSmallMeteor is a factory method which creates a new small meteor, coming in from edge.
+func NewSmallMeteor(baseVelocity float64, edge Edge, g *GameScene, index int, rng *rand.Rand) *Meteor {
+	return newMeteor(MeteorSmall, baseVelocity, edge, g, index, rng)
+}
+
+// newMeteor creates a meteor of the given size, coming in from edge and heading for the middle
+// of the screen.
+func newMeteor(size MeteorSize, baseVelocity float64, edge Edge, g *GameScene, index int, rng *rand.Rand) *Meteor {
+	tier := meteorTiers[size]
+
 	// Target the center of the screen.
 	target := Vector{
 		X: ScreenWidth / 2,
 		Y: ScreenHeight / 2,
 	}
 
-	// Pick a random angle. 2π is 360°, so this returns an angle between 0° to 360°.
-	angle := rand.Float64() * 2 * math.Pi
+	// Pick a random angle on the side it's coming in from.
+	angle := edge.spawnAngle(rng)
 
 	// The distance from the center that meteor should spawn at. Half the width, add some arbitrary distance.
 	r := ScreenWidth/2.0 + 500
@@ -49,7 +129,7 @@ func NewMeteor(baseVelocity float64, g *GameScene, index int) *Meteor {
 
 	// Keep the meteor moving towards the center of the screen.
 	// Give it a random velocity.
-	velocity := baseVelocity + rand.Float64()*1.5
+	velocity := baseVelocity + rng.Float64()*1.5
 
 	// Create the direction vector and normalize it.
 	direction := Vector{
@@ -65,88 +145,68 @@ func NewMeteor(baseVelocity float64, g *GameScene, index int) *Meteor {
 	}
 
 	// Assign a sprite to the meteor.
-	sprite := assets.MeteorSprites[rand.Intn(len(assets.MeteorSprites))]
+	sprite := tier.sprites[rng.IntN(len(tier.sprites))]
 
 	// Create the collision object.
-	meteorObj := resolv.NewCircle(pos.X, pos.Y, float64(sprite.Bounds().Dx()/2))
+	meteorObj := resolv.NewCircle(pos.X, pos.Y, tier.radius)
 
 	// Create a meteor object and return it.
 	m := &Meteor{
 		game:          g,
+		size:          size,
+		material:      MaterialRock,
 		position:      pos,
 		movement:      movement,
-		rotationSpeed: rotationSpeedMin + rand.Float64()*(rotationSpeedMax-rotationSpeedMin),
+		rotationSpeed: rotationSpeedMin + rng.Float64()*(rotationSpeedMax-rotationSpeedMin),
 		sprite:        sprite,
 		angle:         angle,
 		meteorObj:     meteorObj,
 	}
 
 	m.meteorObj.SetPosition(pos.X, pos.Y)
-	m.meteorObj.Tags().Set(TagMeteor | TagLarge)
+	m.meteorObj.Tags().Set(TagMeteor | tier.tag)
 	m.meteorObj.SetData(&ObjectData{index: index})
 
 	return m
 }
 
-// NewSmallMeteor is a factory method which creates a new small meteor.
-func NewSmallMeteor(baseVelocity float64, g *GameScene, index int) *Meteor {
-	// Target the center of the screen.
-	target := Vector{
-		X: ScreenWidth / 2,
-		Y: ScreenHeight / 2,
-	}
-
-	// Pick a random angle. 2π is 360°, so this returns an angle between 0° to 360°.
-	angle := rand.Float64() * 2 * math.Pi
-
-	// The distance from the center that meteor should spawn at. Half the width, add some arbitrary distance.
-	r := ScreenWidth/2.0 + 500
-
-	// Create the position vector, using the angle and simple math.
-	pos := Vector{
-		X: target.X + math.Cos(angle)*r,
-		Y: target.Y + math.Sin(angle)*r,
+// centre returns the middle of the meteor's sprite.
+func (m *Meteor) centre() Vector {
+	bounds := m.sprite.Bounds()
+	return Vector{
+		X: m.position.X + float64(bounds.Dx())/2,
+		Y: m.position.Y + float64(bounds.Dy())/2,
 	}
+}
 
-	// Keep the meteor moving towards the center of the screen.
-	// Give it a random velocity.
-	velocity := baseVelocity + rand.Float64()*1.5
-
-	// Create the direction vector and normalize it.
-	direction := Vector{
-		X: target.X - pos.X,
-		Y: target.Y - pos.Y,
+// hitsToBreak returns how many hits the meteor takes to break. That's one, unless it's iron.
+func (m *Meteor) hitsToBreak(t *Tuning) int {
+	if m.material != MaterialIron {
+		return 1
 	}
-	normalizedDirection := direction.Normalize()
 
-	// Create the movement vector.
-	movement := Vector{
-		X: normalizedDirection.X * velocity,
-		Y: normalizedDirection.Y * velocity,
+	// Smaller iron meteors take one fewer hit for each size down from large.
+	hits := t.Materials.IronHits
+	for size := MeteorLarge; size != m.size && size != ""; size = meteorTiers[size].breaksInto {
+		hits--
 	}
+	return max(hits, 1)
+}
 
-	// Assign a sprite to the meteor.
-	sprite := assets.MeteorSpritesSmall[rand.Intn(len(assets.MeteorSpritesSmall))]
-
-	// Create the collision object.
-	meteorObj := resolv.NewCircle(pos.X, pos.Y, float64(sprite.Bounds().Dx()/2))
+// isExploding reports whether the meteor has been hit, and is waiting to be cleaned up.
+func (m *Meteor) isExploding() bool {
+	return m.sprite == meteorTiers[m.size].explosion
+}
 
-	// Create a meteor object and return it.
-	m := &Meteor{
-		game:          g,
-		position:      pos,
-		movement:      movement,
-		rotationSpeed: rotationSpeedMin + rand.Float64()*(rotationSpeedMax-rotationSpeedMin),
-		sprite:        sprite,
-		angle:         angle,
-		meteorObj:     meteorObj,
+// place puts the meteor at pos, moving with velocity (scaled by speed), or sitting still if
+// velocity is nil. It's for meteors the level puts in a particular spot.
+func (m *Meteor) place(pos Vector, velocity *Vector, speed float64) {
+	m.position = pos
+	m.movement = Vector{}
+	if velocity != nil {
+		m.movement = Vector{X: velocity.X * speed, Y: velocity.Y * speed}
 	}
-
 	m.meteorObj.SetPosition(pos.X, pos.Y)
-	m.meteorObj.Tags().Set(TagMeteor | TagSmall)
-	m.meteorObj.SetData(&ObjectData{index: index})
-
-	return m
 }
 
 // Update updates all game scene elements for the next draw. It's called once per tick.
@@ -157,6 +217,9 @@ func (m *Meteor) Update() {
 	m.position.X += dx
 	m.position.Y += dy
 	m.rotation += m.rotationSpeed
+	if m.hitFlash > 0 {
+		m.hitFlash--
+	}
 
 	// Keep meteor on screen.
 	m.keepOnScreen()
@@ -176,6 +239,20 @@ func (m *Meteor) Draw(screen *ebiten.Image) {
 
 	op.GeoM.Translate(m.position.X, m.position.Y)
 
+	look := meteorLooks[m.material]
+	r, g, b := look.tint[0], look.tint[1], look.tint[2]
+	switch {
+	case m.isExploding():
+		r, g, b = look.explosion[0], look.explosion[1], look.explosion[2]
+	case m.hitFlash > 0:
+		r, g, b = 2, 2, 2
+	case m.damage > 0:
+		// Damaged iron glows hotter with every hit.
+		heat := float32(m.damage) * 0.3
+		r, g, b = r+heat, g+heat/3, b-heat/3
+	}
+	op.ColorScale.Scale(r, g, b, 1)
+
 	screen.DrawImage(m.sprite, op)
 }
 
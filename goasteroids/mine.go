@@ -0,0 +1,76 @@
+package goasteroids
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/solarlune/resolv"
+)
+
+const mineRadius = 10 // The size of a mine, to look at and to set off.
+
+// Mine is what the mine layer drops. It sits where it was dropped, and once it's armed, blows up
+// when a meteor or alien touches it, taking everything close by with it.
+type Mine struct {
+	game      *GameScene     // The current game scene.
+	position  Vector         // Where the middle of it is.
+	armTimer  *Timer         // How long until it's armed.
+	lifeTimer *Timer         // How long until it fizzles out.
+	spent     bool           // Has it gone off?
+	mineObj   *resolv.Circle // The collision object.
+}
+
+// NewMine is a factory method which creates a mine at pos.
+func NewMine(pos Vector, g *GameScene) *Mine {
+	m := &Mine{
+		game:      g,
+		position:  pos,
+		armTimer:  NewTimer(time.Duration(g.tuning.Weapons.MineArmTime)),
+		lifeTimer: NewTimer(time.Duration(g.tuning.Weapons.MineLifetime)),
+		mineObj:   resolv.NewCircle(pos.X, pos.Y, mineRadius),
+	}
+	m.mineObj.Tags().Set(TagLaser)
+
+	return m
+}
+
+// Update counts down to the mine arming, and fizzling out. It's called once per tick.
+func (m *Mine) Update() {
+	m.armTimer.Update()
+	m.lifeTimer.Update()
+}
+
+// Draw draws the mine: grey while it's arming, and blinking red once it's armed.
+func (m *Mine) Draw(screen *ebiten.Image) {
+	c := color.RGBA{R: 150, G: 150, B: 150, A: 255}
+	if m.armTimer.IsReady() && (m.lifeTimer.currentTicks/15)%2 == 0 {
+		c = color.RGBA{R: 255, G: 60, B: 60, A: 255}
+	}
+	vector.StrokeCircle(screen, float32(m.position.X), float32(m.position.Y), mineRadius, 2, c, true)
+	vector.DrawFilledCircle(screen, float32(m.position.X), float32(m.position.Y), 3, c, true)
+}
+
+func (m *Mine) shape() resolv.IShape {
+	return m.mineObj
+}
+
+func (m *Mine) point() Vector {
+	return m.position
+}
+
+// strike sets the mine off if it's armed and target touches it. It never hits target itself: the
+// blast does that, along with everything else in reach.
+func (m *Mine) strike(target resolv.IShape) bool {
+	if m.spent || !m.armTimer.IsReady() || !m.mineObj.IsIntersecting(target) {
+		return false
+	}
+	m.spent = true
+	m.game.explode(m.position, m.game.tuning.Weapons.MineRadius, creditPlayer)
+	return false
+}
+
+func (m *Mine) isSpent() bool {
+	return m.spent || m.lifeTimer.IsReady()
+}
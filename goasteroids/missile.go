@@ -0,0 +1,126 @@
+package goasteroids
+
+import (
+	"asteroids/assets"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/solarlune/resolv"
+)
+
+const missileRadius = 6 // The size of a missile's collision circle.
+
+// Missile is what the missile launcher fires. It's locked on to an alien or a large meteor, and
+// turns towards it as it flies, as fast as its turn rate lets it. If what it's locked on to goes,
+// it locks on to whatever's in front of it next. Once its fuel's gone it can't steer any more, and
+// flies straight on until it burns out. It blows up on the first thing it hits.
+type Missile struct {
+	game       *GameScene     // The current game scene.
+	position   Vector         // Where the middle of it is.
+	rotation   float64        // Which way it's heading.
+	sprite     *ebiten.Image  // The image.
+	target     resolv.IShape  // What it's locked on to, if anything.
+	fuelTimer  *Timer         // How long it can steer for.
+	lifeTimer  *Timer         // How long until it burns out.
+	spent      bool           // Has it hit something?
+	missileObj *resolv.Circle // The collision object.
+}
+
+// NewMissile is a factory method which creates a missile at pos, heading at rotation and locked
+// on to target (which can be nil, if there's nothing to lock on to yet).
+func NewMissile(pos Vector, rotation float64, target resolv.IShape, g *GameScene) *Missile {
+	m := &Missile{
+		game:       g,
+		position:   pos,
+		rotation:   rotation,
+		sprite:     assets.LaserSprite,
+		target:     target,
+		fuelTimer:  NewTimer(time.Duration(g.tuning.Weapons.MissileFuel)),
+		lifeTimer:  NewTimer(time.Duration(g.tuning.Weapons.MissileLifetime)),
+		missileObj: resolv.NewCircle(pos.X, pos.Y, missileRadius),
+	}
+	m.missileObj.Tags().Set(TagLaser)
+
+	return m
+}
+
+// Update turns the missile towards its target, and moves it along. It's called once per tick.
+func (m *Missile) Update() {
+	t := m.game.tuning.Weapons
+
+	if m.hasFuel() {
+		target, ok := m.targetCentre()
+		if ok {
+			limit := t.MissileTurnRate * math.Pi / 180 / TicksPerSecond
+			m.rotation = turnTowards(m.rotation, target.Sub(m.position).Rotation(), limit)
+		}
+		m.fuelTimer.Update()
+	}
+
+	m.position = m.position.Add(heading(m.rotation, t.MissileSpeed/TicksPerSecond))
+	m.missileObj.SetPosition(m.position.X, m.position.Y)
+
+	m.lifeTimer.Update()
+}
+
+// hasFuel reports whether the missile can still steer.
+func (m *Missile) hasFuel() bool {
+	return !m.fuelTimer.IsReady()
+}
+
+// targetCentre returns the middle of what the missile's locked on to. If that's gone, it locks on
+// to whatever's in front of it instead, if there's anything.
+func (m *Missile) targetCentre() (Vector, bool) {
+	if m.target != nil {
+		if centre, ok := m.game.targetCentre(m.target); ok {
+			return centre, true
+		}
+	}
+
+	m.target = m.game.lockOn(m.position, m.rotation)
+	if m.target == nil {
+		return Vector{}, false
+	}
+	return m.game.targetCentre(m.target)
+}
+
+// Draw draws the missile, an orange laser bolt pointing the way it's going. It goes dull once it's
+// out of fuel.
+func (m *Missile) Draw(screen *ebiten.Image) {
+	bounds := m.sprite.Bounds()
+	halfW := float64(bounds.Dx()) / 2
+	halfH := float64(bounds.Dy()) / 2
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-halfW, -halfH)
+	op.GeoM.Rotate(m.rotation)
+	op.GeoM.Translate(m.position.X, m.position.Y)
+	if m.hasFuel() {
+		op.ColorScale.Scale(1.6, 0.8, 0.2, 1)
+	} else {
+		op.ColorScale.Scale(0.8, 0.5, 0.3, 1)
+	}
+
+	screen.DrawImage(m.sprite, op)
+}
+
+func (m *Missile) shape() resolv.IShape {
+	return m.missileObj
+}
+
+func (m *Missile) point() Vector {
+	return m.position
+}
+
+func (m *Missile) strike(target resolv.IShape) bool {
+	if m.spent || !m.missileObj.IsIntersecting(target) {
+		return false
+	}
+	m.spent = true
+	return true
+}
+
+func (m *Missile) isSpent() bool {
+	return m.spent || m.lifeTimer.IsReady() || isOffscreen(m.position)
+}
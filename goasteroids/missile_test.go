@@ -0,0 +1,141 @@
+package goasteroids
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMissileTurnRateIsClamped(t *testing.T) {
+	g := emptyGame(t)
+	target := placeMeteor(g, MeteorLarge, Vector{X: 600, Y: 400})
+
+	// The missile's heading straight up, with its target off to the right.
+	m := NewMissile(Vector{X: 400, Y: 400}, 0, target.meteorObj, g)
+	limit := g.tuning.Weapons.MissileTurnRate * math.Pi / 180 / TicksPerSecond
+
+	m.Update()
+	if !closeTo(m.rotation, limit) {
+		t.Fatalf("rotation after a tick = %v, want the turn rate's %v", m.rotation, limit)
+	}
+
+	// However it has to turn to keep after its target, it never turns faster than that.
+	for range TicksPerSecond {
+		before := m.rotation
+		m.Update()
+		if turn := math.Abs(math.Remainder(m.rotation-before, 2*math.Pi)); turn > limit+1e-9 {
+			t.Fatalf("missile turned %v in a tick, more than the turn rate's %v", turn, limit)
+		}
+	}
+}
+
+func TestMissileTurnsOntoTarget(t *testing.T) {
+	g := emptyGame(t)
+	target := placeMeteor(g, MeteorLarge, Vector{X: 700, Y: 100})
+
+	// Heading a little off to the left of it, the missile comes round within half a second, and then
+	// holds its course.
+	m := NewMissile(Vector{X: 400, Y: 400}, 0, target.meteorObj, g)
+	for range 30 {
+		m.Update()
+	}
+	want := target.centre().Sub(m.position).Rotation()
+	if math.Abs(math.Remainder(m.rotation-want, 2*math.Pi)) > 0.01 {
+		t.Errorf("rotation = %v, want it to have come round to %v", m.rotation, want)
+	}
+}
+
+func TestMissileFliesStraightWithoutFuel(t *testing.T) {
+	g := emptyGame(t)
+	target := placeMeteor(g, MeteorLarge, Vector{X: 600, Y: 400})
+
+	m := NewMissile(Vector{X: 400, Y: 400}, 0, target.meteorObj, g)
+	m.fuelTimer = NewTimer(0)
+
+	m.Update()
+	if m.rotation != 0 {
+		t.Errorf("a missile with no fuel turned to %v", m.rotation)
+	}
+}
+
+func TestLockOn(t *testing.T) {
+	pos := Vector{X: 400, Y: 400}
+
+	tests := []struct {
+		name    string
+		size    MeteorSize
+		at      Vector
+		locksOn bool
+	}{
+		{"large meteor dead ahead", MeteorLarge, Vector{X: 400, Y: 100}, true},
+		{"large meteor inside the cone", MeteorLarge, Vector{X: 600, Y: 150}, true},
+		{"large meteor outside the cone", MeteorLarge, Vector{X: 700, Y: 350}, false},
+		{"large meteor behind", MeteorLarge, Vector{X: 400, Y: 700}, false},
+		{"large meteor out of range", MeteorLarge, Vector{X: 400, Y: -400}, false},
+		{"medium meteor", MeteorMedium, Vector{X: 400, Y: 100}, false},
+		{"small meteor", MeteorSmall, Vector{X: 400, Y: 100}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := emptyGame(t)
+			m := placeMeteor(g, tt.size, tt.at)
+
+			target := g.lockOn(pos, 0)
+			if tt.locksOn && target != m.meteorObj {
+				t.Errorf("didn't lock on")
+			}
+			if !tt.locksOn && target != nil {
+				t.Errorf("locked on")
+			}
+		})
+	}
+}
+
+func TestLockOnPicksNearest(t *testing.T) {
+	g := emptyGame(t)
+	placeMeteor(g, MeteorLarge, Vector{X: 400, Y: 50})
+	near := placeMeteor(g, MeteorLarge, Vector{X: 450, Y: 250})
+	placeMeteor(g, MeteorLarge, Vector{X: 300, Y: 150})
+
+	if target := g.lockOn(Vector{X: 400, Y: 400}, 0); target != near.meteorObj {
+		t.Errorf("didn't lock on to the nearest meteor")
+	}
+}
+
+func TestMissileRetargetsWhenTargetBreaks(t *testing.T) {
+	g := emptyGame(t)
+	first := placeMeteor(g, MeteorLarge, Vector{X: 400, Y: 250})
+	second := placeMeteor(g, MeteorLarge, Vector{X: 500, Y: 100})
+
+	m := NewMissile(Vector{X: 400, Y: 400}, 0, g.lockOn(Vector{X: 400, Y: 400}, 0), g)
+	if m.target != first.meteorObj {
+		t.Fatal("missile didn't lock on to the nearer meteor")
+	}
+
+	// Someone else breaks it. Its pieces are too small to lock on to, so the missile goes after
+	// the other one.
+	g.hitMeteor(first, first.centre(), creditPlayer)
+	m.Update()
+	if m.target != second.meteorObj {
+		t.Errorf("missile didn't lock on to the other meteor once its target broke")
+	}
+	if m.rotation <= 0 {
+		t.Errorf("missile didn't turn towards its new target, rotation %v", m.rotation)
+	}
+}
+
+func TestMissileBurnsOut(t *testing.T) {
+	g := emptyGame(t)
+	m := NewMissile(Vector{X: 400, Y: 400}, math.Pi/2, nil, g)
+	m.lifeTimer = NewTimer(100 * time.Millisecond)
+
+	for range 100 * TicksPerSecond / 1000 {
+		if m.isSpent() {
+			t.Fatal("missile burnt out early")
+		}
+		m.Update()
+	}
+	if !m.isSpent() {
+		t.Error("missile didn't burn out")
+	}
+}
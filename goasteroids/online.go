@@ -0,0 +1,122 @@
+package goasteroids
+
+import (
+	"asteroids/leaderboard"
+	"context"
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const leaderboardRetryInterval = time.Minute // How often queued scores are retried.
+
+var (
+	onlineBoard *leaderboard.Client // The shared leaderboard, or nil when we're only keeping scores locally.
+
+	globalMu       sync.Mutex
+	globalScores   HighScores // The shared table, as of the last fetch.
+	globalFetched  bool       // Has a fetch ever worked?
+	globalFetching bool       // Is a fetch under way?
+)
+
+// UseLeaderboard sends finished games to the leaderboard server at url, and shows its table
+// alongside ours. Games that can't be sent straight away are queued in the data directory and
+// retried in the background.
+func UseLeaderboard(url string) error {
+	dir, err := dataDir()
+	if err != nil {
+		return err
+	}
+
+	c, err := leaderboard.NewClient(url, filepath.Join(dir, "leaderboard-queue.json"))
+	if c == nil {
+		return err
+	}
+
+	onlineBoard = c
+	go c.Run(context.Background(), leaderboardRetryInterval)
+	refreshGlobalScores()
+	return err
+}
+
+// submitOnline sends the game g played, under initials, to the leaderboard (if there is one),
+// along with its replay so the server can check it. It doesn't wait for the server.
+func submitOnline(g *GameScene, initials string) {
+	if onlineBoard == nil {
+		return
+	}
+
+	replay, err := g.replay.MarshalBinary()
+	if err != nil {
+		log.Println("Error encoding replay for the leaderboard:", err)
+		return
+	}
+
+	s := leaderboard.Submission{
+		ID:       leaderboard.NewID(),
+		Initials: initials,
+		Score:    g.Score(),
+		Level:    g.Level(),
+		Mode:     string(g.Mode()),
+		Seed:     g.Seed(),
+		Date:     time.Now().UTC(),
+		Replay:   replay,
+	}
+
+	go func() {
+		if err := onlineBoard.Submit(context.Background(), s); err != nil {
+			log.Println("Error submitting score (it'll be retried if it can be):", err)
+			return
+		}
+		refreshGlobalScores()
+	}()
+}
+
+// refreshGlobalScores fetches the shared table in the background, if there's a leaderboard and
+// we're not already fetching it.
+func refreshGlobalScores() {
+	if onlineBoard == nil {
+		return
+	}
+
+	globalMu.Lock()
+	if globalFetching {
+		globalMu.Unlock()
+		return
+	}
+	globalFetching = true
+	globalMu.Unlock()
+
+	go func() {
+		entries, err := onlineBoard.Top(context.Background(), leaderboard.Query{Limit: maxHighScores})
+
+		globalMu.Lock()
+		defer globalMu.Unlock()
+		globalFetching = false
+		if err != nil {
+			log.Println("Error fetching leaderboard:", err)
+			return
+		}
+
+		globalScores = make(HighScores, 0, len(entries))
+		for _, e := range entries {
+			globalScores = append(globalScores, HighScore{
+				Initials: e.Initials,
+				Score:    e.Score,
+				Level:    e.Level,
+				Date:     e.Date,
+				Mode:     Mode(e.Mode),
+			})
+		}
+		globalFetched = true
+	}()
+}
+
+// globalHighScores returns the shared table as of the last fetch, and whether a fetch has
+// ever worked.
+func globalHighScores() (HighScores, bool) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	return globalScores, globalFetched
+}
@@ -0,0 +1,218 @@
+package goasteroids
+
+import (
+	"asteroids/assets"
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	volumeStep      = 0.1  // How much left and right change a volume by.
+	starDensityStep = 0.25 // How much left and right change the star density by.
+)
+
+const (
+	optionMusic = iota
+	optionSFX
+	optionFullscreen
+	optionWindowSize
+	optionStars
+	optionDifficulty
+	optionLevels
+	optionControls
+	optionBack
+)
+
+// OptionsScene lets the player change the settings. It's pushed on top of the title or the pause
+// menu, changes take effect as soon as they're made, and they're saved when the player leaves.
+type OptionsScene struct {
+	settings  Settings // The settings being edited.
+	levelSets []string // The names of the level sets to pick from.
+	menu      *Menu
+}
+
+// NewOptionsScene creates an options screen showing the settings in use.
+func NewOptionsScene() *OptionsScene {
+	sets, err := LoadLevelSets()
+	if err != nil {
+		log.Println("Error loading level sets:", err)
+	}
+
+	o := &OptionsScene{
+		settings: settings,
+		menu: NewMenu(
+			MenuItem{}, // The labels show the values, so they're filled in by refresh.
+			MenuItem{},
+			MenuItem{},
+			MenuItem{},
+			MenuItem{},
+			MenuItem{},
+			MenuItem{},
+			MenuItem{Label: "CONTROLS"},
+			MenuItem{Label: "BACK"},
+		),
+	}
+	for _, s := range sets {
+		o.levelSets = append(o.levelSets, s.Name)
+	}
+	o.refresh()
+	return o
+}
+
+// Update handles the menu. Left and right change the highlighted setting, and confirm steps it
+// on. It's called once per tick.
+func (o *OptionsScene) Update(state *State) error {
+	if state.Input.IsJustPressed(ActionPause) {
+		o.leave(state)
+		return nil
+	}
+
+	switch {
+	case state.Input.IsJustPressed(ActionRotateLeft):
+		o.change(o.menu.Selected(), -1)
+	case state.Input.IsJustPressed(ActionRotateRight):
+		o.change(o.menu.Selected(), 1)
+	}
+
+	choice, ok := o.menu.Update(state.Input)
+	if !ok {
+		return nil
+	}
+
+	switch choice {
+	case optionControls:
+		state.SceneManager.PushScene(NewControlsScene(), pauseTransition)
+	case optionBack:
+		o.leave(state)
+	default:
+		o.change(choice, 1)
+	}
+
+	return nil
+}
+
+// change steps setting i on by step, and applies the result straight away.
+func (o *OptionsScene) change(i int, step int) {
+	s := &o.settings
+	switch i {
+	case optionMusic:
+		s.MusicVolume = stepValue(s.MusicVolume, step, volumeStep, 0, 1)
+	case optionSFX:
+		s.SFXVolume = stepValue(s.SFXVolume, step, volumeStep, 0, 1)
+	case optionFullscreen:
+		s.Fullscreen = !s.Fullscreen
+	case optionWindowSize:
+		current := 0
+		for j, size := range windowSizes {
+			if size == s.Window {
+				current = j
+			}
+		}
+		s.Window = windowSizes[(current+step+len(windowSizes))%len(windowSizes)]
+	case optionStars:
+		s.StarDensity = stepValue(s.StarDensity, step, starDensityStep, 0, 2)
+	case optionDifficulty:
+		current := 0
+		for j, n := range difficultyNames {
+			if n.difficulty == s.Difficulty {
+				current = j
+			}
+		}
+		s.Difficulty = difficultyNames[(current+step+len(difficultyNames))%len(difficultyNames)].difficulty
+	case optionLevels:
+		current := slices.Index(o.levelSets, s.LevelSet)
+		if current < 0 {
+			current = 0
+		}
+		s.LevelSet = o.levelSets[(current+step+len(o.levelSets))%len(o.levelSets)]
+	default:
+		return
+	}
+
+	UseSettings(o.settings)
+	o.refresh()
+}
+
+// stepValue moves v by step lots of size, keeping it between lo and hi. It rounds to the nearest
+// step, so repeated steps don't drift.
+func stepValue(v float64, step int, size, lo, hi float64) float64 {
+	v = math.Round(v/size+float64(step)) * size
+	return min(max(v, lo), hi)
+}
+
+// refresh updates the menu to show the current values.
+func (o *OptionsScene) refresh() {
+	s := o.settings
+
+	fullscreen := "OFF"
+	if s.Fullscreen {
+		fullscreen = "ON"
+	}
+
+	o.menu.SetLabel(optionMusic, fmt.Sprintf("MUSIC VOLUME  %d%%", int(math.Round(s.MusicVolume*100))))
+	o.menu.SetLabel(optionSFX, fmt.Sprintf("SFX VOLUME  %d%%", int(math.Round(s.SFXVolume*100))))
+	o.menu.SetLabel(optionFullscreen, "FULLSCREEN  "+fullscreen)
+	o.menu.SetLabel(optionWindowSize, fmt.Sprintf("WINDOW SIZE  %dx%d", s.Window.Width, s.Window.Height))
+	o.menu.SetLabel(optionStars, fmt.Sprintf("STARS  %d%%", int(math.Round(s.StarDensity*100))))
+	o.menu.SetLabel(optionDifficulty, "DIFFICULTY  "+strings.ToUpper(s.Difficulty.String()))
+	o.menu.SetLabel(optionLevels, "LEVELS  "+strings.ToUpper(s.LevelSet))
+}
+
+// leave saves the settings and goes back to wherever we came from.
+func (o *OptionsScene) leave(state *State) {
+	if err := SaveSettings(o.settings); err != nil {
+		log.Println("Error saving settings:", err)
+	}
+	state.SceneManager.PopScene(pauseTransition)
+}
+
+// DrawsBelow keeps whatever we came from drawn under the menu.
+func (o *OptionsScene) DrawsBelow() bool {
+	return true
+}
+
+// UpdatesBelow freezes whatever we came from.
+func (o *OptionsScene) UpdatesBelow() bool {
+	return false
+}
+
+// Draw dims the scene below and draws the menu over it. It's called once per frame.
+func (o *OptionsScene) Draw(screen *ebiten.Image) {
+	// Dim harder than the pause menu does, since it may be what's underneath.
+	vector.DrawFilledRect(screen, 0, 0, ScreenWidth, ScreenHeight, color.RGBA{A: 0xe8}, false)
+
+	op := &text.DrawOptions{
+		LayoutOptions: text.LayoutOptions{
+			PrimaryAlign: text.AlignCenter,
+		},
+	}
+	op.ColorScale.ScaleWithColor(color.White)
+	op.GeoM.Translate(ScreenWidth/2, ScreenHeight/2-300)
+	text.Draw(screen, "OPTIONS", &text.GoTextFace{
+		Source: assets.TitleFont,
+		Size:   48,
+	}, op)
+
+	o.menu.Draw(screen, ScreenWidth/2, ScreenHeight/2-200)
+
+	op = &text.DrawOptions{
+		LayoutOptions: text.LayoutOptions{
+			PrimaryAlign: text.AlignCenter,
+		},
+	}
+	op.ColorScale.ScaleWithColor(color.White)
+	op.ColorScale.ScaleAlpha(0.5)
+	op.GeoM.Translate(ScreenWidth/2, ScreenHeight/2+260)
+	text.Draw(screen, "LEFT/RIGHT TO CHANGE   DIFFICULTY AND LEVELS APPLY TO THE NEXT GAME", &text.GoTextFace{
+		Source: assets.ScoreFont,
+		Size:   12,
+	}, op)
+}
@@ -0,0 +1,102 @@
+package goasteroids
+
+import (
+	"asteroids/assets"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const pauseTransitionTicks = 10 // Pausing should feel quicker than changing scenes.
+
+var pauseTransition = FadeOver(pauseTransitionTicks)
+
+const (
+	pauseResume = iota
+	pauseRestart
+	pauseOptions
+	pauseQuit
+)
+
+// PauseScene is pushed on top of a game to freeze it and show a menu over it. The game isn't
+// stepped while we're here, and every Timer in it only moves when the game is stepped, so
+// shields, cooldowns, drift and spawns all wait for us.
+type PauseScene struct {
+	game *GameScene
+	menu *Menu
+}
+
+// NewPauseScene pauses g, and silences the sounds that would otherwise keep looping.
+func NewPauseScene(g *GameScene) *PauseScene {
+	g.audio.Pause(SoundThrust)
+	g.audio.Pause(SoundBeatOne)
+	g.audio.Pause(SoundBeatTwo)
+	g.audio.Pause(SoundAlien)
+
+	return &PauseScene{
+		game: g,
+		menu: NewMenu(
+			MenuItem{Label: "RESUME"},
+			MenuItem{Label: "RESTART"},
+			MenuItem{Label: "OPTIONS"},
+			MenuItem{Label: "QUIT TO TITLE"},
+		),
+	}
+}
+
+// Update handles the menu. Pressing pause again resumes. It's called once per tick.
+func (p *PauseScene) Update(state *State) error {
+	if state.Input.IsJustPressed(ActionPause) {
+		state.SceneManager.PopScene(pauseTransition)
+		return nil
+	}
+
+	choice, ok := p.menu.Update(state.Input)
+	if !ok {
+		return nil
+	}
+
+	switch choice {
+	case pauseResume:
+		state.SceneManager.PopScene(pauseTransition)
+	case pauseOptions:
+		state.SceneManager.PushScene(NewOptionsScene(), pauseTransition)
+	case pauseRestart:
+		state.SceneManager.GoToScene(p.game.nextGame())
+	case pauseQuit:
+		state.SceneManager.GoToScene(NewTitleScene(p.game.titleSeed()))
+	}
+
+	return nil
+}
+
+// DrawsBelow keeps the game drawn under the menu.
+func (p *PauseScene) DrawsBelow() bool {
+	return true
+}
+
+// UpdatesBelow freezes the game while we're paused.
+func (p *PauseScene) UpdatesBelow() bool {
+	return false
+}
+
+// Draw dims the frozen game and draws the menu over it. It's called once per frame.
+func (p *PauseScene) Draw(screen *ebiten.Image) {
+	vector.DrawFilledRect(screen, 0, 0, ScreenWidth, ScreenHeight, color.RGBA{A: 0xb0}, false)
+
+	op := &text.DrawOptions{
+		LayoutOptions: text.LayoutOptions{
+			PrimaryAlign: text.AlignCenter,
+		},
+	}
+	op.ColorScale.ScaleWithColor(color.White)
+	op.GeoM.Translate(ScreenWidth/2, ScreenHeight/2-200)
+	text.Draw(screen, "PAUSED", &text.GoTextFace{
+		Source: assets.TitleFont,
+		Size:   48,
+	}, op)
+
+	p.menu.Draw(screen, ScreenWidth/2, ScreenHeight/2-60)
+}
@@ -3,34 +3,18 @@ package goasteroids
 import (
 	"asteroids/assets"
 	"math"
-	"math/rand/v2"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/solarlune/resolv"
 )
 
 const (
-	rotationPerSecond    = math.Pi
-	maxAcceleration      = 8.0
-	ScreenWidth          = 1280 // The width of the screen. We use a 16/9 aspect ratio.
-	ScreenHeight         = 720  // The height of the screen.
-	shootCoolDown        = time.Millisecond * 150
-	burstCoolDown        = time.Millisecond * 500
-	laserSpawnOffset     = 50.0
-	maxShotsPerBurst     = 3
-	dyingAnimationAmount = 50 * time.Millisecond
-	numberOfLives        = 3
-	numberOfShields      = 3
-	shieldDuration       = time.Second * 6
-	hyperSpaceCooldown   = time.Second * 10
-	driftTime            = time.Second * 30
+	ScreenWidth      = 1280 // The width of the screen. We use a 16/9 aspect ratio.
+	ScreenHeight     = 720  // The height of the screen.
+	laserSpawnOffset = 50.0
 )
 
-var curAcceleration float64 // We use this to gradually increase acceleration.
-var shotsFired = 0          // A counter to keep track of max shots per burst.
-
 type Player struct {
 	game                *GameScene           // The current game scene.
 	sprite              *ebiten.Image        // The player's sprite.
@@ -38,8 +22,6 @@ type Player struct {
 	position            Vector               // Where is the player on the screen.
 	playerVelocity      float64              // How fast is the player moving.
 	playerObj           *resolv.Circle       // The player's collision object.
-	shootCoolDown       *Timer               // Pause between shots.
-	burstCoolDown       *Timer               // Pause between bursts of shots.
 	isShielded          bool                 // Is the player currently shielded?
 	isDying             bool                 // Is the player dying?
 	isDead              bool                 // Is the player dead?
@@ -54,6 +36,10 @@ type Player struct {
 	hyperSpaceTimer     *Timer               // The player's hyperspace cooldown timer.
 	driftTimer          *Timer               // The player's drift timer.
 	driftAngle          float64              // The player's drift angle.
+	curAcceleration     float64              // We use this to gradually increase acceleration.
+	weapons             []Weapon             // The weapons the ship carries.
+	weapon              int                  // Which of the weapons is in use.
+	effects             *Effects             // The timed power-ups working right now.
 }
 
 // NewPlayer is a factory method for creating a new player.
@@ -75,7 +61,7 @@ func NewPlayer(game *GameScene) *Player {
 
 	var lifeIndicators []*LifeIndicator
 	var xPosition = 20.0
-	for i := 0; i < numberOfLives; i++ {
+	for i := 0; i < game.tuning.Player.Lives; i++ {
 		li := NewLifeIndicator(Vector{X: xPosition, Y: 20})
 		lifeIndicators = append(lifeIndicators, li)
 		xPosition += 50.0
@@ -83,7 +69,7 @@ func NewPlayer(game *GameScene) *Player {
 
 	var shieldIndicators []*ShieldIndicator
 	xPosition = 45.0
-	for i := 0; i < numberOfShields; i++ {
+	for i := 0; i < game.tuning.Player.Shields; i++ {
 		si := NewShieldIndicator(Vector{X: xPosition, Y: 60})
 		shieldIndicators = append(shieldIndicators, si)
 		xPosition += 50.0
@@ -94,16 +80,15 @@ func NewPlayer(game *GameScene) *Player {
 		game:                game,
 		position:            pos,
 		playerObj:           playerObj,
-		shootCoolDown:       NewTimer(shootCoolDown),
-		burstCoolDown:       NewTimer(burstCoolDown),
+		effects:             NewEffects(Vector{X: 20, Y: 140}, game),
 		isShielded:          false,
 		isDying:             false,
 		isDead:              false,
-		dyingTimer:          NewTimer(dyingAnimationAmount),
+		dyingTimer:          NewTimer(time.Duration(game.tuning.Player.DyingAnimation)),
 		dyingCounter:        0,
-		livesRemaining:      numberOfLives,
+		livesRemaining:      game.tuning.Player.Lives,
 		lifeIndicators:      lifeIndicators,
-		shieldsRemaining:    numberOfShields,
+		shieldsRemaining:    game.tuning.Player.Shields,
 		shieldIndicators:    shieldIndicators,
 		hyperspaceIndicator: NewHyperspaceIndicator(Vector{X: 37.0, Y: 95.0}),
 		hyperSpaceTimer:     nil,
@@ -112,6 +97,7 @@ func NewPlayer(game *GameScene) *Player {
 
 	p.playerObj.SetPosition(pos.X, pos.Y)
 	p.playerObj.Tags().Set(TagPlayer)
+	p.armWeapons(game.tuning)
 
 	return p
 }
@@ -135,15 +121,15 @@ func (p *Player) Draw(screen *ebiten.Image) {
 
 // Update updates the player for the next draw. Called once per tick.
 func (p *Player) Update() {
-	speed := rotationPerSecond / float64(ebiten.TPS())
+	speed := p.game.tuning.Player.RotationPerSecond / TicksPerSecond
 
 	p.isPlayerDead()
 
-	if ebiten.IsKeyPressed(ebiten.KeyLeft) {
+	if p.game.isPressed(ActionRotateLeft) {
 		p.rotation -= speed
 	}
 
-	if ebiten.IsKeyPressed(ebiten.KeyRight) {
+	if p.game.isPressed(ActionRotateRight) {
 		p.rotation += speed
 	}
 
@@ -165,11 +151,11 @@ func (p *Player) Update() {
 
 	p.playerObj.SetPosition(p.position.X, p.position.Y)
 
-	p.burstCoolDown.Update()
+	p.cycleWeapon()
 
-	p.shootCoolDown.Update()
+	p.currentWeapon().Update(p, p.game.isPressed(ActionFire))
 
-	p.fireLasers()
+	p.effects.Update()
 
 	p.hyperSpace()
 
@@ -184,7 +170,7 @@ func (p *Player) isPlayerDrifting() {
 
 		p.driftTimer.Update()
 
-		decelerationSpeed := p.playerVelocity / float64(ebiten.TPS()) * 4
+		decelerationSpeed := p.playerVelocity / TicksPerSecond * 4
 
 		p.position.X += math.Sin(p.driftAngle) * decelerationSpeed
 		p.position.Y += math.Cos(p.driftAngle) * -decelerationSpeed
@@ -200,12 +186,14 @@ func (p *Player) isDriftFinished() {
 }
 
 func (p *Player) hyperSpace() {
-	if ebiten.IsKeyPressed(ebiten.KeyH) && (p.hyperSpaceTimer == nil || p.hyperSpaceTimer.IsReady()) {
+	if p.game.isPressed(ActionHyperspace) && (p.hyperSpaceTimer == nil || p.hyperSpaceTimer.IsReady()) {
 		var randX, randY int
 		for {
-			randX = rand.IntN(ScreenWidth)
-			randY = rand.IntN(ScreenHeight)
+			randX = p.game.random.hyperspace.IntN(ScreenWidth)
+			randY = p.game.random.hyperspace.IntN(ScreenHeight)
 
+			// Try the new spot before checking it, otherwise we'd test the old one forever.
+			p.playerObj.SetPosition(float64(randX), float64(randY))
 			collision := p.game.checkCollision(p.playerObj, nil)
 			if !collision {
 				break
@@ -216,23 +204,21 @@ func (p *Player) hyperSpace() {
 		p.position.Y = float64(randY)
 
 		if p.hyperSpaceTimer == nil {
-			p.hyperSpaceTimer = NewTimer(hyperSpaceCooldown)
+			p.hyperSpaceTimer = NewTimer(time.Duration(p.game.tuning.Player.HyperspaceCooldown))
 		}
 		p.hyperSpaceTimer.Reset()
 	}
 }
 
 func (p *Player) useShield() {
-	if ebiten.IsKeyPressed(ebiten.KeyS) && !p.isShielded && p.shieldsRemaining > 0 {
-		if !p.game.shieldsUpPlayer.IsPlaying() {
-			_ = p.game.shieldsUpPlayer.Rewind()
-			p.game.shieldsUpPlayer.Play()
-		}
+	if p.game.isPressed(ActionShield) && !p.isShielded && p.shieldsRemaining > 0 {
+		p.game.audio.Play(SoundShieldsUp)
 
 		p.isShielded = true
-		p.shieldTimer = NewTimer(shieldDuration)
+		p.shieldTimer = NewTimer(time.Duration(p.game.tuning.Player.ShieldDuration))
 		p.game.shield = NewShield(Vector{}, p.rotation, p.game)
 		p.shieldsRemaining--
+		p.game.stats.shields++
 		p.shieldIndicators = p.shieldIndicators[:len(p.shieldIndicators)-1]
 	}
 
@@ -255,72 +241,127 @@ func (p *Player) isPlayerDead() {
 	}
 }
 
-// fireLasers fires a laser and plays a sound.
-func (p *Player) fireLasers() {
-	if p.burstCoolDown.IsReady() {
-		if p.shootCoolDown.IsReady() && ebiten.IsKeyPressed(ebiten.KeySpace) {
-			p.shootCoolDown.Reset()
-			shotsFired++
-			if shotsFired <= maxShotsPerBurst {
-				bounds := p.sprite.Bounds()
-				halfW := float64(bounds.Dx()) / 2
-				halfH := float64(bounds.Dy()) / 2
-
-				spawnPos := Vector{
-					p.position.X + halfW + math.Sin(p.rotation)*laserSpawnOffset,
-					p.position.Y + halfH + math.Cos(p.rotation)*-laserSpawnOffset,
-				}
-
-				p.game.laserCount++
-				laser := NewLaser(spawnPos, p.rotation, p.game.laserCount, p.game)
-				p.game.lasers[p.game.laserCount] = laser
-				p.game.space.Add(laser.laserObj)
-
-				switch shotsFired {
-				case 1:
-					if !p.game.laserOnePlayer.IsPlaying() {
-						_ = p.game.laserOnePlayer.Rewind()
-						p.game.laserOnePlayer.Play()
-					}
-				case 2:
-					if !p.game.laserTwoPlayer.IsPlaying() {
-						_ = p.game.laserTwoPlayer.Rewind()
-						p.game.laserTwoPlayer.Play()
-					}
-				case 3:
-					if !p.game.laserThreePlayer.IsPlaying() {
-						_ = p.game.laserThreePlayer.Rewind()
-						p.game.laserThreePlayer.Play()
-					}
-				}
-			} else {
-				p.burstCoolDown.Reset()
-				shotsFired = 0
-			}
+// armWeapons gives the ship the weapons in tuning t's loadout. The weapon in use stays in use, if
+// it's still in the loadout.
+func (p *Player) armWeapons(t *Tuning) {
+	var current WeaponKind
+	if len(p.weapons) > 0 {
+		current = p.currentWeapon().Kind()
+	}
+
+	p.weapons = nil
+	p.weapon = 0
+	for i, kind := range t.Weapons.Loadout {
+		p.weapons = append(p.weapons, newWeapon(kind, t))
+		if kind == current {
+			p.weapon = i
 		}
 	}
 }
 
+// currentWeapon returns the weapon in use.
+func (p *Player) currentWeapon() Weapon {
+	return p.weapons[p.weapon]
+}
+
+// cycleWeapon switches to the next weapon the ship carries.
+func (p *Player) cycleWeapon() {
+	if p.game.isJustPressed(ActionCycleWeapon) {
+		p.currentWeapon().Holster()
+		p.weapon = (p.weapon + 1) % len(p.weapons)
+	}
+}
+
+// centre returns the middle of the ship.
+func (p *Player) centre() Vector {
+	bounds := p.sprite.Bounds()
+	return Vector{
+		X: p.position.X + float64(bounds.Dx())/2,
+		Y: p.position.Y + float64(bounds.Dy())/2,
+	}
+}
+
+// velocity returns how far the ship's moving each tick: forwards while it's thrusting, backwards
+// while it's reversing, and on the way it was going while it drifts.
+func (p *Player) velocity() Vector {
+	switch {
+	case p.game.isPressed(ActionThrust):
+		return heading(p.rotation, p.curAcceleration)
+	case p.game.isPressed(ActionReverse):
+		return heading(p.rotation, -3)
+	case p.driftTimer != nil:
+		return heading(p.driftAngle, p.playerVelocity/TicksPerSecond*4)
+	}
+	return Vector{}
+}
+
+// nose returns the point just in front of the ship, where it shoots from.
+func (p *Player) nose() Vector {
+	bounds := p.sprite.Bounds()
+	halfW := float64(bounds.Dx()) / 2
+	halfH := float64(bounds.Dy()) / 2
+
+	return Vector{
+		p.position.X + halfW + math.Sin(p.rotation)*laserSpawnOffset,
+		p.position.Y + halfH + math.Cos(p.rotation)*-laserSpawnOffset,
+	}
+}
+
+// tail returns the point just behind the ship, where mines are dropped.
+func (p *Player) tail() Vector {
+	bounds := p.sprite.Bounds()
+	halfW := float64(bounds.Dx()) / 2
+	halfH := float64(bounds.Dy()) / 2
+
+	return Vector{
+		p.position.X + halfW - math.Sin(p.rotation)*laserSpawnOffset,
+		p.position.Y + halfH + math.Cos(p.rotation)*laserSpawnOffset,
+	}
+}
+
+// fireLaser fires one laser from the nose of the ship, heading at rotation.
+func (p *Player) fireLaser(rotation float64) {
+	p.game.addProjectile(NewLaser(p.nose(), rotation, p.game.projectileCount+1, p.game))
+}
+
+// addLife gives the player another life, unless they've got as many as they can have.
+func (p *Player) addLife() {
+	if p.livesRemaining < p.game.tuning.Player.MaxLives {
+		p.livesRemaining++
+		x := float64(20 + len(p.lifeIndicators)*50.0)
+		y := 20.0
+		p.lifeIndicators = append(p.lifeIndicators, NewLifeIndicator(Vector{X: x, Y: y}))
+	}
+}
+
+// addShield gives the player another shield charge.
+func (p *Player) addShield() {
+	p.shieldsRemaining++
+	x := float64(45 + len(p.shieldIndicators)*50.0)
+	y := 60.0
+	p.shieldIndicators = append(p.shieldIndicators, NewShieldIndicator(Vector{X: x, Y: y}))
+}
+
 // accelerate moves the player forward in whatever direction they are pointing and plays a sound.
 func (p *Player) accelerate() {
-	if ebiten.IsKeyPressed(ebiten.KeyUp) {
+	if p.game.isPressed(ActionThrust) {
 		p.driftTimer = nil
 
 		p.keepOnScreen()
 
-		if curAcceleration < maxAcceleration {
-			curAcceleration = p.playerVelocity + 4
+		if p.curAcceleration < p.game.tuning.Player.MaxAcceleration {
+			p.curAcceleration = p.playerVelocity + 4
 		}
 
-		if curAcceleration >= 8 {
-			curAcceleration = 8
+		if p.curAcceleration >= p.game.tuning.Player.MaxAcceleration {
+			p.curAcceleration = p.game.tuning.Player.MaxAcceleration
 		}
 
-		p.playerVelocity = curAcceleration
+		p.playerVelocity = p.curAcceleration
 
 		// Move in the direction we are pointing.
-		dx := math.Sin(p.rotation) * curAcceleration
-		dy := math.Cos(p.rotation) * -curAcceleration
+		dx := math.Sin(p.rotation) * p.curAcceleration
+		dy := math.Cos(p.rotation) * -p.curAcceleration
 
 		// Show exhaust.
 		bounds := p.sprite.Bounds()
@@ -333,40 +374,35 @@ func (p *Player) accelerate() {
 			p.position.Y + halfH + math.Cos(p.rotation)*-exhaustSpawnOffset,
 		}
 
-		p.game.exhaust = NewExhaust(spawnPos, p.rotation+180.0*math.Pi/180.0)
+		p.game.exhaust = NewExhaust(spawnPos, p.rotation+180.0*math.Pi/180.0, p.game.tuning.Player.MaxAcceleration)
 
 		// Move the player on the screen.
 		p.position.X += dx
 		p.position.Y += dy
 
-		if !p.game.thrustPlayer.IsPlaying() {
-			_ = p.game.thrustPlayer.Rewind()
-			p.game.thrustPlayer.Play()
-		}
+		p.game.audio.Play(SoundThrust)
 	}
 }
 
-// isDoneAccelerating pauses the thrust sound when the KeyUp is released.
+// isDoneAccelerating pauses the thrust sound when thrust is released.
 func (p *Player) isDoneAccelerating() {
-	if inpututil.IsKeyJustReleased(ebiten.KeyUp) {
-		if p.game.thrustPlayer.IsPlaying() {
-			p.game.thrustPlayer.Pause()
-		}
+	if p.game.isJustReleased(ActionThrust) {
+		p.game.audio.Pause(SoundThrust)
 
 		// Figure out velocity.
-		if p.playerVelocity < curAcceleration * 10 {
+		if p.playerVelocity < p.curAcceleration * 10 {
 			// Subtact a bit from speed.
-			p.playerVelocity = curAcceleration * 10 - 5.0
+			p.playerVelocity = p.curAcceleration * 10 - 5.0
 		}
 
 		if p.playerVelocity < 0 {
 			p.playerVelocity = 0
 		}
 
-		curAcceleration = 0
+		p.curAcceleration = 0
 
 		// Create a drift timer.
-		p.driftTimer = NewTimer(driftTime)
+		p.driftTimer = NewTimer(time.Duration(p.game.tuning.Player.DriftTime))
 
 		// Save angele of rotation.
 		p.driftAngle = p.rotation
@@ -375,7 +411,7 @@ func (p *Player) isDoneAccelerating() {
 
 // reverse moves the player backwards and plays a sound.
 func (p *Player) reverse() {
-	if ebiten.IsKeyPressed(ebiten.KeyDown) {
+	if p.game.isPressed(ActionReverse) {
 		p.driftTimer = nil
 
 		p.keepOnScreen()
@@ -392,32 +428,27 @@ func (p *Player) reverse() {
 			p.position.Y + halfH + math.Cos(p.rotation)*exhaustSpawnOffset,
 		}
 
-		p.game.exhaust = NewExhaust(spawnPos, p.rotation+180.0*math.Pi/180.0)
+		p.game.exhaust = NewExhaust(spawnPos, p.rotation+180.0*math.Pi/180.0, p.game.tuning.Player.MaxAcceleration)
 
 		p.position.X += dx
 		p.position.Y += dy
 
 		p.playerObj.SetPosition(p.position.X, p.position.Y)
 
-		if !p.game.thrustPlayer.IsPlaying() {
-			_ = p.game.thrustPlayer.Rewind()
-			p.game.thrustPlayer.Play()
-		}
+		p.game.audio.Play(SoundThrust)
 	}
 }
 
-// isDoneReversing stops the thrust sound when keyDown is released.
+// isDoneReversing stops the thrust sound when reverse is released.
 func (p *Player) isDoneReversing() {
-	if inpututil.IsKeyJustReleased(ebiten.KeyDown) {
-		if p.game.thrustPlayer.IsPlaying() {
-			p.game.thrustPlayer.Pause()
-		}
+	if p.game.isJustReleased(ActionReverse) {
+		p.game.audio.Pause(SoundThrust)
 	}
 }
 
 // updateExhaustSprite hides the exhaust sprite when the exhaust is nil, or the player has stopped moving.
 func (p *Player) updateExhaustSprite() {
-	if !ebiten.IsKeyPressed(ebiten.KeyUp) && !ebiten.IsKeyPressed(ebiten.KeyDown) && p.game.exhaust != nil {
+	if !p.game.isPressed(ActionThrust) && !p.game.isPressed(ActionReverse) && p.game.exhaust != nil {
 		p.game.exhaust = nil
 	}
 }
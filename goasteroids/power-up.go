@@ -0,0 +1,222 @@
+package goasteroids
+
+import (
+	"asteroids/assets"
+	"fmt"
+	"image/color"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/solarlune/resolv"
+)
+
+const (
+	powerUpRadius    = 18              // How big a power-up is, to look at and to pick up.
+	powerUpBlinkTime = 2 * time.Second // Power-ups blink for this long before they're gone.
+)
+
+// PowerUpKind is what a power-up does when it's picked up.
+type PowerUpKind string
+
+const (
+	PowerUpShield     PowerUpKind = "shield"      // An extra shield charge.
+	PowerUpRapidFire  PowerUpKind = "rapid-fire"  // Shoot faster, with no pause between bursts, for a while.
+	PowerUpSpreadShot PowerUpKind = "spread-shot" // Shoot three lasers at once for a while.
+	PowerUpHyperspace PowerUpKind = "hyperspace"  // Hyperspace is ready again straight away.
+	PowerUpExtraLife  PowerUpKind = "extra-life"  // An extra life.
+	PowerUpMultiplier PowerUpKind = "multiplier"  // Points are multiplied for a while.
+)
+
+// powerUpKinds are all the kinds of power-up, in the order they're shown in the HUD.
+var powerUpKinds = []PowerUpKind{
+	PowerUpShield,
+	PowerUpRapidFire,
+	PowerUpSpreadShot,
+	PowerUpHyperspace,
+	PowerUpExtraLife,
+	PowerUpMultiplier,
+}
+
+// powerUpLook is how a kind of power-up is drawn.
+type powerUpLook struct {
+	label  string     // The letters in the middle. The multiplier shows what it multiplies by instead.
+	colour color.RGBA // The ring and the letters.
+}
+
+var powerUpLooks = map[PowerUpKind]powerUpLook{
+	PowerUpShield:     {label: "S", colour: color.RGBA{R: 80, G: 160, B: 255, A: 255}},
+	PowerUpRapidFire:  {label: "R", colour: color.RGBA{R: 255, G: 80, B: 80, A: 255}},
+	PowerUpSpreadShot: {label: "3", colour: color.RGBA{R: 255, G: 200, B: 60, A: 255}},
+	PowerUpHyperspace: {label: "H", colour: color.RGBA{R: 180, G: 100, B: 255, A: 255}},
+	PowerUpExtraLife:  {label: "1UP", colour: color.RGBA{R: 80, G: 255, B: 120, A: 255}},
+	PowerUpMultiplier: {label: "X", colour: color.RGBA{R: 255, G: 255, B: 255, A: 255}},
+}
+
+// label returns the letters a power-up of kind is shown with.
+func (k PowerUpKind) label(t *Tuning) string {
+	if k == PowerUpMultiplier {
+		return fmt.Sprintf("X%d", t.PowerUps.Multiplier)
+	}
+	return powerUpLooks[k].label
+}
+
+// isTimed reports whether the power-up's effect lasts a while, rather than happening once.
+func (k PowerUpKind) isTimed() bool {
+	return k == PowerUpRapidFire || k == PowerUpSpreadShot || k == PowerUpMultiplier
+}
+
+// PowerUp is a power-up, drifting about (and wrapping around the screen) until it's picked up or
+// it runs out of time.
+type PowerUp struct {
+	game       *GameScene     // The current game scene.
+	kind       PowerUpKind    // What it does.
+	position   Vector         // Where the middle of it is.
+	movement   Vector         // How it drifts.
+	lifeTimer  *Timer         // How long until it's gone.
+	powerUpObj *resolv.Circle // The collision object.
+}
+
+// NewPowerUp is a factory method which creates a new power-up of kind at pos, drifting off in a
+// direction drawn from rng.
+func NewPowerUp(kind PowerUpKind, pos Vector, g *GameScene, index int, rng *rand.Rand) *PowerUp {
+	angle := rng.Float64() * 2 * math.Pi
+	speed := g.tuning.PowerUps.DriftSpeed
+
+	p := &PowerUp{
+		game:       g,
+		kind:       kind,
+		position:   pos,
+		movement:   Vector{X: math.Cos(angle) * speed, Y: math.Sin(angle) * speed},
+		lifeTimer:  NewTimer(time.Duration(g.tuning.PowerUps.Lifetime)),
+		powerUpObj: resolv.NewCircle(pos.X, pos.Y, powerUpRadius),
+	}
+
+	p.powerUpObj.SetPosition(pos.X, pos.Y)
+	p.powerUpObj.Tags().Set(TagPowerUp)
+	p.powerUpObj.SetData(&ObjectData{index: index})
+
+	return p
+}
+
+// Update drifts the power-up along. It's called once per tick.
+func (p *PowerUp) Update() {
+	p.position.X += p.movement.X
+	p.position.Y += p.movement.Y
+
+	// Wrap around the screen, like meteors do.
+	if p.position.X >= ScreenWidth {
+		p.position.X = 0
+	}
+	if p.position.X < 0 {
+		p.position.X = ScreenWidth
+	}
+	if p.position.Y >= ScreenHeight {
+		p.position.Y = 0
+	}
+	if p.position.Y < 0 {
+		p.position.Y = ScreenHeight
+	}
+
+	p.powerUpObj.SetPosition(p.position.X, p.position.Y)
+	p.lifeTimer.Update()
+}
+
+// isExpired reports whether the power-up has run out of time.
+func (p *PowerUp) isExpired() bool {
+	return p.lifeTimer.IsReady()
+}
+
+// Draw draws the power-up: a ring with its letters in the middle. It blinks when it's about to go.
+func (p *PowerUp) Draw(screen *ebiten.Image) {
+	blinkTicks := int(powerUpBlinkTime.Milliseconds()) * TicksPerSecond / 1000
+	left := p.lifeTimer.targetTicks - p.lifeTimer.currentTicks
+	if left < blinkTicks && (left/8)%2 == 0 {
+		return
+	}
+
+	look := powerUpLooks[p.kind]
+	vector.StrokeCircle(screen, float32(p.position.X), float32(p.position.Y), powerUpRadius, 2, look.colour, true)
+
+	op := &text.DrawOptions{
+		LayoutOptions: text.LayoutOptions{
+			PrimaryAlign:   text.AlignCenter,
+			SecondaryAlign: text.AlignCenter,
+		},
+	}
+	op.ColorScale.ScaleWithColor(look.colour)
+	op.GeoM.Translate(p.position.X, p.position.Y)
+	text.Draw(screen, p.kind.label(p.game.tuning), &text.GoTextFace{
+		Source: assets.ScoreFont,
+		Size:   12,
+	}, op)
+}
+
+// Effects are the timed power-ups working right now. They're shown in the HUD, under the shield
+// and hyperspace indicators, each with a bar for the time it's got left.
+type Effects struct {
+	game     *GameScene             // The current game scene.
+	position Vector                 // Where the first one is shown.
+	timers   map[PowerUpKind]*Timer // How long each has got left.
+}
+
+// NewEffects is a factory method which creates an empty set of effects, shown at pos.
+func NewEffects(pos Vector, g *GameScene) *Effects {
+	return &Effects{
+		game:     g,
+		position: pos,
+		timers:   make(map[PowerUpKind]*Timer),
+	}
+}
+
+// start starts the effect kind, lasting d. Picking up one that's already working starts it again.
+func (e *Effects) start(kind PowerUpKind, d time.Duration) {
+	e.timers[kind] = NewTimer(d)
+}
+
+// has reports whether the effect kind is working.
+func (e *Effects) has(kind PowerUpKind) bool {
+	_, ok := e.timers[kind]
+	return ok
+}
+
+// Update runs the effects' timers down, and stops the ones that have run out. It's called once per
+// tick.
+func (e *Effects) Update() {
+	for kind, t := range e.timers {
+		t.Update()
+		if t.IsReady() {
+			delete(e.timers, kind)
+		}
+	}
+}
+
+// Draw draws each effect that's working, with a bar for the time it's got left.
+func (e *Effects) Draw(screen *ebiten.Image) {
+	y := e.position.Y
+	for _, kind := range powerUpKinds {
+		t, ok := e.timers[kind]
+		if !ok {
+			continue
+		}
+
+		look := powerUpLooks[kind]
+		op := &text.DrawOptions{
+			LayoutOptions: text.LayoutOptions{
+				SecondaryAlign: text.AlignCenter,
+			},
+		}
+		op.ColorScale.ScaleWithColor(look.colour)
+		op.GeoM.Translate(e.position.X, y)
+		text.Draw(screen, kind.label(e.game.tuning), &text.GoTextFace{
+			Source: assets.ScoreFont,
+			Size:   14,
+		}, op)
+
+		vector.DrawFilledRect(screen, float32(e.position.X+40), float32(y-4), float32(80*t.Remaining()), 8, look.colour, false)
+		y += 26
+	}
+}
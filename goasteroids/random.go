@@ -0,0 +1,99 @@
+package goasteroids
+
+import (
+	"hash/fnv"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// menuRand drives the decoration on menu scenes (drifting meteors, stars), where it doesn't
+// matter if things come out differently every time.
+var menuRand = newStream(uint64(time.Now().UnixNano()), "menu")
+
+// RandomStreams holds the random number generators for a game. They're all split from one seed,
+// but each stream only ever feeds one part of the game, so (for example) changing how many stars
+// we draw can't change which meteors come next.
+type RandomStreams struct {
+	seed       uint64     // The seed every stream was split from.
+	meteors    *rand.Rand // Meteor spawns, sizes, speeds and break-ups.
+	aliens     *rand.Rand // Alien spawns, types and shots.
+	stars      *rand.Rand // The background stars.
+	hyperspace *rand.Rand // Where hyperspace jumps land.
+	powerUps   *rand.Rand // Which power-ups drop, and where they drift.
+}
+
+// NewRandomStreams splits seed into one stream per part of the game.
+func NewRandomStreams(seed uint64) *RandomStreams {
+	return &RandomStreams{
+		seed:       seed,
+		meteors:    newStream(seed, "meteors"),
+		aliens:     newStream(seed, "aliens"),
+		stars:      newStream(seed, "stars"),
+		hyperspace: newStream(seed, "hyperspace"),
+		powerUps:   newStream(seed, "power-ups"),
+	}
+}
+
+// Seed returns the seed the streams were split from.
+func (r *RandomStreams) Seed() uint64 {
+	return r.seed
+}
+
+// newStream returns a random number generator that always produces the same numbers for the same
+// seed and name. Different names give unrelated sequences from the same seed.
+func newStream(seed uint64, name string) *rand.Rand {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return rand.New(rand.NewPCG(seed, h.Sum64()))
+}
+
+// NewSeed returns a seed for a game when the player hasn't asked for a particular one.
+func NewSeed() uint64 {
+	return uint64(time.Now().UnixNano())
+}
+
+// seedOrNew returns seed, or a new seed if seed is 0.
+func seedOrNew(seed uint64) uint64 {
+	if seed == 0 {
+		return NewSeed()
+	}
+	return seed
+}
+
+// DailySeed returns the seed for the daily challenge on the day t falls on (in UTC), so everyone
+// playing that day gets the same waves.
+func DailySeed(t time.Time) uint64 {
+	y, m, d := t.UTC().Date()
+	return uint64(y*10000 + int(m)*100 + d)
+}
+
+// Mode is the kind of game being played, as far as the high score table is concerned: scores
+// from a seed everyone can practise aren't really comparable with ones from a fresh seed.
+type Mode string
+
+const (
+	ModeClassic Mode = "classic" // Every game gets a new seed.
+	ModeSeeded  Mode = "seeded"  // The player picked the seed.
+	ModeDaily   Mode = "daily"   // The daily challenge.
+)
+
+// modeFor returns the mode of a game played with seed. fixed says whether the player picked it.
+func modeFor(seed uint64, fixed bool) Mode {
+	switch {
+	case !fixed:
+		return ModeClassic
+	case seed == DailySeed(time.Now()):
+		return ModeDaily
+	default:
+		return ModeSeeded
+	}
+}
+
+// inOrder returns the keys of m from smallest to largest. Go's map iteration order is random, so
+// anything that uses the random number generator or hands out new indexes while looping over a
+// map must loop over inOrder(m) instead, or the same seed could play out differently.
+func inOrder[V any](m map[int]V) []int {
+	return slices.Sorted(maps.Keys(m))
+}
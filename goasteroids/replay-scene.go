@@ -0,0 +1,168 @@
+package goasteroids
+
+import (
+	"asteroids/assets"
+	"fmt"
+	"image/color"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// replaySpeeds are the playback speeds we can pick from.
+var replaySpeeds = []float64{0.25, 0.5, 1, 2, 4, 8}
+
+const normalReplaySpeed = 2 // The index of 1x in replaySpeeds.
+
+// ReplayScene plays a recorded game back by feeding its frames to a fresh GameScene with the same
+// seed. It can be sped up, slowed down, paused, and stepped one tick at a time.
+type ReplayScene struct {
+	game     *GameScene        // The game being played back.
+	replay   *Replay           // The recording.
+	tick     int               // The index of the next frame to play.
+	speed    int               // Index into replaySpeeds.
+	owed     float64           // Ticks we still owe the game at slow speeds, which don't step every tick.
+	paused   bool              // Is playback paused?
+	interval *LevelStartsScene // Draws the "LEVEL n" screen between levels.
+}
+
+// NewReplayScene creates a scene that plays r back from the start.
+func NewReplayScene(r *Replay) *ReplayScene {
+	if r.GameVersion != Version {
+		log.Printf("Replay was recorded with version %s, this is %s; it may not play back the same", r.GameVersion, Version)
+	}
+
+	if r.Tuning != tuning.Fingerprint() {
+		log.Println("Replay was recorded with different tuning; it may not play back the same")
+	}
+
+	levels, err := FindLevelSet(r.Levels)
+	if err != nil {
+		log.Println("Error loading the replay's levels, playing the classic ones:", err)
+		levels = classicLevels()
+	}
+
+	g := newGameScene(r.Seed, r.Difficulty, levels, defaultSpeakers())
+	return &ReplayScene{
+		game:     g,
+		replay:   r,
+		speed:    normalReplaySpeed,
+		interval: &LevelStartsScene{game: g, stars: g.stars},
+	}
+}
+
+// Update handles the playback controls and steps the game as many times as the speed calls for.
+// It's called once per tick.
+func (r *ReplayScene) Update(state *State) error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyQ) {
+		r.stopSounds()
+		state.SceneManager.GoToScene(NewTitleScene(0))
+		return nil
+	}
+
+	if r.isFinished() {
+		r.stopSounds()
+		if state.Input.IsJustPressed(ActionConfirm) {
+			state.SceneManager.GoToScene(NewTitleScene(0))
+		}
+		return nil
+	}
+
+	if state.Input.IsJustPressed(ActionPause) {
+		r.paused = !r.paused
+		r.owed = 0
+		if r.paused {
+			r.stopSounds()
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEqual) || inpututil.IsKeyJustPressed(ebiten.KeyBracketRight) {
+		r.speed = min(r.speed+1, len(replaySpeeds)-1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyMinus) || inpututil.IsKeyJustPressed(ebiten.KeyBracketLeft) {
+		r.speed = max(r.speed-1, 0)
+	}
+
+	if r.paused {
+		// Step one tick at a time.
+		if inpututil.IsKeyJustPressed(ebiten.KeyPeriod) {
+			r.step()
+		}
+		return nil
+	}
+
+	r.owed += replaySpeeds[r.speed]
+	for r.owed >= 1 && !r.isFinished() {
+		r.step()
+		r.owed--
+	}
+
+	return nil
+}
+
+// step plays the next recorded frame.
+func (r *ReplayScene) step() {
+	if r.isFinished() {
+		return
+	}
+	r.game.Step(r.replay.Frames[r.tick])
+	r.tick++
+}
+
+// isFinished reports whether we've run out of frames, or the game is over.
+func (r *ReplayScene) isFinished() bool {
+	return r.tick >= len(r.replay.Frames) || r.game.phase == PhaseGameOver
+}
+
+// stopSounds stops any sound that would otherwise keep looping while nothing's happening.
+func (r *ReplayScene) stopSounds() {
+	r.game.audio.Pause(SoundThrust)
+	r.game.audio.Pause(SoundAlien)
+}
+
+// Draw draws the game being played back, with the playback state over the top. It's called once
+// per frame.
+func (r *ReplayScene) Draw(screen *ebiten.Image) {
+	if r.game.phase == PhaseLevelStarting {
+		r.interval.Draw(screen)
+	} else {
+		r.game.Draw(screen)
+	}
+
+	status := fmt.Sprintf("REPLAY %gx", replaySpeeds[r.speed])
+	switch {
+	case r.isFinished():
+		status = "END OF REPLAY"
+	case r.paused:
+		status = "REPLAY PAUSED"
+	}
+
+	textToDraw := fmt.Sprintf("%s  %d/%d", status, r.tick, len(r.replay.Frames))
+	op := &text.DrawOptions{
+		LayoutOptions: text.LayoutOptions{
+			PrimaryAlign: text.AlignEnd,
+		},
+	}
+	op.ColorScale.ScaleWithColor(color.White)
+	op.GeoM.Translate(ScreenWidth-20, 20)
+	text.Draw(screen, textToDraw, &text.GoTextFace{
+		Source: assets.ScoreFont,
+		Size:   16,
+	}, op)
+
+	textToDraw = "PAUSE: P   SPEED: - +   STEP: .   QUIT: Q"
+	op = &text.DrawOptions{
+		LayoutOptions: text.LayoutOptions{
+			PrimaryAlign: text.AlignEnd,
+		},
+	}
+	op.ColorScale.ScaleWithColor(color.White)
+	op.ColorScale.ScaleAlpha(0.5)
+	op.GeoM.Translate(ScreenWidth-20, 45)
+	text.Draw(screen, textToDraw, &text.GoTextFace{
+		Source: assets.ScoreFont,
+		Size:   12,
+	}, op)
+}
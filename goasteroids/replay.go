@@ -0,0 +1,257 @@
+package goasteroids
+
+import (
+	"asteroids/internal/atomicfile"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	replayMagic   = "GARP" // Every replay file starts with this.
+	replayVersion = 1      // Bump this when the layout of replay files changes.
+
+	// maxReplayTicks stops a corrupt (or hostile) file from making us allocate forever. It's
+	// about ten hours of play.
+	maxReplayTicks = 10 * 60 * 60 * TicksPerSecond
+)
+
+// Replay is a recording of one game: the seed, difficulty and levels it was played with, and the
+// input for every tick. Feeding the frames to a new game with the same seed, difficulty and levels
+// plays it out exactly the same way.
+type Replay struct {
+	GameVersion string       // The version of the game that recorded it.
+	Seed        uint64       // The seed the game was played with.
+	Difficulty  Difficulty   // The difficulty the game was played at.
+	Tuning      uint64       // The Fingerprint of the tuning the game was played with, or 0 if it's not known.
+	Levels      string       // The name of the level set the game was played with.
+	Frames      []InputFrame // The input for every tick, in order.
+}
+
+// NewReplay starts an empty recording of a game played with seed at difficulty d, using the
+// tuning with fingerprint tuning and the level set called levels.
+func NewReplay(seed uint64, d Difficulty, tuning uint64, levels string) *Replay {
+	return &Replay{
+		GameVersion: Version,
+		Seed:        seed,
+		Difficulty:  d,
+		Tuning:      tuning,
+		Levels:      levels,
+	}
+}
+
+// MarshalBinary encodes the replay as:
+//
+//	magic "GARP" | format version (1 byte) | game version (uvarint length + bytes) |
+//	seed (8 bytes, little endian) | difficulty (1 byte) | tuning (8 bytes, little endian) |
+//	level set (uvarint length + bytes) | number of runs (uvarint) | runs
+//
+// Input rarely changes from one tick to the next, so frames are stored as runs of
+// (frame, count) uvarint pairs, which keeps a long game down to a few kilobytes.
+func (r *Replay) MarshalBinary() ([]byte, error) {
+	type run struct {
+		frame InputFrame
+		count uint64
+	}
+
+	var runs []run
+	for _, f := range r.Frames {
+		if len(runs) > 0 && runs[len(runs)-1].frame == f {
+			runs[len(runs)-1].count++
+			continue
+		}
+		runs = append(runs, run{frame: f, count: 1})
+	}
+
+	b := []byte(replayMagic)
+	b = append(b, replayVersion)
+	b = binary.AppendUvarint(b, uint64(len(r.GameVersion)))
+	b = append(b, r.GameVersion...)
+	b = binary.LittleEndian.AppendUint64(b, r.Seed)
+	b = append(b, byte(r.Difficulty))
+	b = binary.LittleEndian.AppendUint64(b, r.Tuning)
+	b = binary.AppendUvarint(b, uint64(len(r.Levels)))
+	b = append(b, r.Levels...)
+	b = binary.AppendUvarint(b, uint64(len(runs)))
+	for _, rn := range runs {
+		b = binary.AppendUvarint(b, uint64(rn.frame))
+		b = binary.AppendUvarint(b, rn.count)
+	}
+
+	return b, nil
+}
+
+// UnmarshalBinary decodes a replay written by MarshalBinary.
+func (r *Replay) UnmarshalBinary(data []byte) error {
+	buf := bytes.NewReader(data)
+
+	magic := make([]byte, len(replayMagic))
+	if _, err := io.ReadFull(buf, magic); err != nil || string(magic) != replayMagic {
+		return errors.New("not a replay file")
+	}
+
+	version, err := buf.ReadByte()
+	if err != nil {
+		return err
+	}
+	if version != replayVersion {
+		return fmt.Errorf("unsupported replay version %d", version)
+	}
+
+	n, err := binary.ReadUvarint(buf)
+	if err != nil {
+		return err
+	}
+	if n > uint64(buf.Len()) {
+		return errors.New("replay is truncated")
+	}
+	gameVersion := make([]byte, n)
+	if _, err := io.ReadFull(buf, gameVersion); err != nil {
+		return err
+	}
+
+	var seed uint64
+	if err := binary.Read(buf, binary.LittleEndian, &seed); err != nil {
+		return err
+	}
+
+	d, err := buf.ReadByte()
+	if err != nil {
+		return err
+	}
+	difficulty := Difficulty(d)
+	if _, err := difficulty.MarshalText(); err != nil {
+		return err
+	}
+
+	var tuning uint64
+	if err := binary.Read(buf, binary.LittleEndian, &tuning); err != nil {
+		return err
+	}
+
+	n, err = binary.ReadUvarint(buf)
+	if err != nil {
+		return err
+	}
+	if n > uint64(buf.Len()) {
+		return errors.New("replay is truncated")
+	}
+	levels := make([]byte, n)
+	if _, err := io.ReadFull(buf, levels); err != nil {
+		return err
+	}
+
+	runs, err := binary.ReadUvarint(buf)
+	if err != nil {
+		return err
+	}
+
+	var frames []InputFrame
+	for i := uint64(0); i < runs; i++ {
+		frame, err := binary.ReadUvarint(buf)
+		if err != nil {
+			return err
+		}
+		if frame > math.MaxUint16 {
+			return fmt.Errorf("bad input frame %d", frame)
+		}
+		count, err := binary.ReadUvarint(buf)
+		if err != nil {
+			return err
+		}
+		if count > maxReplayTicks || uint64(len(frames))+count > maxReplayTicks {
+			return errors.New("replay is too long")
+		}
+		for j := uint64(0); j < count; j++ {
+			frames = append(frames, InputFrame(frame))
+		}
+	}
+
+	r.GameVersion = string(gameVersion)
+	r.Seed = seed
+	r.Difficulty = difficulty
+	r.Tuning = tuning
+	r.Levels = string(levels)
+	r.Frames = frames
+	return nil
+}
+
+// SimulationResult is how a replayed game turned out.
+type SimulationResult struct {
+	Score    int  // The final score.
+	Level    int  // The level reached.
+	Ticks    int  // How many frames were played before the game ended (or the frames ran out).
+	GameOver bool // Did the game end?
+}
+
+// Simulate plays r back on a headless game, as fast as it can, and reports how it turned out. It
+// stops when the game ends, even if there are frames left over, and gives up with ctx's error if
+// ctx is done first. The game is played with the tuning in use, which only plays r back
+// faithfully if r.Tuning matches its Fingerprint. It fails if r's level set can't be found.
+func Simulate(ctx context.Context, r *Replay) (SimulationResult, error) {
+	levels, err := FindLevelSet(r.Levels)
+	if err != nil {
+		return SimulationResult{}, err
+	}
+	g := NewHeadlessGameScene(r.Seed, r.Difficulty, levels)
+
+	ticks := 0
+	for _, frame := range r.Frames {
+		// Checking every tick would slow things down for nothing; once a second of game time is plenty.
+		if ticks%TicksPerSecond == 0 {
+			if err := ctx.Err(); err != nil {
+				return SimulationResult{}, err
+			}
+		}
+
+		g.Step(frame)
+		ticks++
+		if g.Phase() == PhaseGameOver {
+			break
+		}
+	}
+
+	return SimulationResult{
+		Score:    g.Score(),
+		Level:    g.Level(),
+		Ticks:    ticks,
+		GameOver: g.Phase() == PhaseGameOver,
+	}, nil
+}
+
+// LoadReplay reads a replay file.
+func LoadReplay(path string) (*Replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Replay{}
+	if err := r.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// SaveReplay writes r to the replays folder, and returns where it went.
+func SaveReplay(r *Replay) (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := r.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, "replays", fmt.Sprintf("%s-%d.replay", time.Now().Format("20060102-150405"), r.Seed))
+	return path, atomicfile.WriteFile(path, data, 0640)
+}
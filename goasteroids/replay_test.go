@@ -0,0 +1,49 @@
+package goasteroids
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestReplayRoundTrip(t *testing.T) {
+	r := NewReplay(1234567890, DifficultyHard, 0xfeedface, "gauntlet")
+	for n := range 500 {
+		r.Frames = append(r.Frames, spinAndShoot(n/7))
+	}
+
+	data, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := &Replay{}
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if got.GameVersion != r.GameVersion || got.Seed != r.Seed || got.Difficulty != r.Difficulty ||
+		got.Tuning != r.Tuning || got.Levels != r.Levels {
+		t.Errorf("header = %+v, want %+v", *got, *r)
+	}
+	if !slices.Equal(got.Frames, r.Frames) {
+		t.Errorf("frames don't match: got %d, want %d", len(got.Frames), len(r.Frames))
+	}
+}
+
+func TestReplayRejectsOtherVersions(t *testing.T) {
+	data, err := NewReplay(1, DifficultyNormal, 0, ClassicLevels).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, version := range []byte{0, replayVersion + 1} {
+		bad := slices.Clone(data)
+		bad[len(replayMagic)] = version
+		if err := (&Replay{}).UnmarshalBinary(bad); err == nil {
+			t.Errorf("version %d was accepted", version)
+		}
+	}
+
+	if err := (&Replay{}).UnmarshalBinary(data[:len(data)-2]); err == nil {
+		t.Error("a truncated replay was accepted")
+	}
+}
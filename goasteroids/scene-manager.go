@@ -1,6 +1,10 @@
 package goasteroids
 
-import "github.com/hajimehoshi/ebiten/v2"
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
 
 var (
 	transitionFrom = ebiten.NewImage(ScreenWidth, ScreenHeight)
@@ -9,6 +13,37 @@ var (
 
 const transitionMaxCount = 25
 
+// TransitionKind is the way one set of scenes gives way to the next.
+type TransitionKind int
+
+const (
+	TransitionFade TransitionKind = iota // The new scenes fade in over the old ones.
+	TransitionNone                       // The new scenes appear straight away.
+	TransitionWipe                       // The new scenes slide in over the old ones from the left.
+)
+
+// Transition is how we move between scenes, and how many ticks it takes.
+type Transition struct {
+	Kind     TransitionKind
+	Duration int
+}
+
+// Fade is the transition GoToScene uses: a cross-fade lasting transitionMaxCount ticks.
+var Fade = Transition{Kind: TransitionFade, Duration: transitionMaxCount}
+
+// NoTransition switches scenes immediately.
+var NoTransition = Transition{Kind: TransitionNone}
+
+// FadeOver returns a cross-fade lasting ticks ticks.
+func FadeOver(ticks int) Transition {
+	return Transition{Kind: TransitionFade, Duration: ticks}
+}
+
+// WipeOver returns a wipe lasting ticks ticks.
+func WipeOver(ticks int) Transition {
+	return Transition{Kind: TransitionWipe, Duration: ticks}
+}
+
 // Scene is the interface for all scenes. In order to be a scene, we have to implement all the functions
 // for this interface.
 type Scene interface {
@@ -16,48 +51,101 @@ type Scene interface {
 	Draw(screen *ebiten.Image)
 }
 
+// Overlay is implemented by scenes that are pushed on top of another scene (a pause menu, say)
+// and want a say in what happens to the scene underneath. A pushed scene that isn't an Overlay
+// covers everything below it, and everything below it freezes.
+type Overlay interface {
+	// DrawsBelow reports whether the scene underneath should still be drawn.
+	DrawsBelow() bool
+	// UpdatesBelow reports whether the scene underneath should keep running. It gets no input.
+	UpdatesBelow() bool
+}
+
 // State is the type for game state. All we need to keep track of is the Scene (with SceneManager)
-// and Input (so we can get the keys pressed on the keyboard).
+// and Input (so we know which actions the player is doing).
 type State struct {
 	SceneManager *SceneManager
-	Input        *Input 
+	Input        *Input
 }
 
-// SceneManager is the type used to manage scenes. We keep track of what scene we are on, and when
-// going to a new scene, what that scene is (so we can fade things in & out nicely).
+// SceneManager is the type used to manage scenes. Scenes are kept on a stack: the top one gets
+// the input, and overlays (see Overlay) can let the ones below keep drawing or running. When the
+// stack changes we keep both the old and the new stack for a while, so we can fade (or wipe)
+// between them.
 type SceneManager struct {
-	current         Scene
-	next            Scene
-	transitionCount int
+	stack           []Scene    // The scenes, bottom first.
+	next            []Scene    // The stack we're moving to, while transitioning.
+	transition      Transition // How we're moving to next.
+	transitionCount int        // Ticks left in the transition.
+	noInput         Input      // Handed to scenes that keep running under an overlay.
 }
 
-// Draw draws the scene. Note that it transitions between scenes using transitionCount (which is set to
-// transitionMaxCount initially when moving between scenes).
+// Draw draws the scenes. While transitioning, it draws both the old and the new stack and blends
+// them according to the kind of transition and how far through it we are.
 func (s *SceneManager) Draw(r *ebiten.Image) {
 	if s.transitionCount == 0 {
-		s.current.Draw(r)
+		drawStack(r, s.stack)
 		return
 	}
 
 	transitionFrom.Clear()
-	s.current.Draw(transitionFrom)
+	drawStack(transitionFrom, s.stack)
 
 	transitionTo.Clear()
-	s.next.Draw(transitionTo)
+	drawStack(transitionTo, s.next)
 
 	r.DrawImage(transitionFrom, nil)
 
-	alpha := 1 - float32(s.transitionCount)/float32(transitionMaxCount)
-	op := &ebiten.DrawImageOptions{}
-	op.ColorScale.ScaleAlpha(alpha)
-	r.DrawImage(transitionTo, op)
+	progress := 1 - float32(s.transitionCount)/float32(s.transition.Duration)
+	switch s.transition.Kind {
+	case TransitionWipe:
+		// Uncover the new scenes from the left edge.
+		w := int(progress * ScreenWidth)
+		r.DrawImage(transitionTo.SubImage(image.Rect(0, 0, w, ScreenHeight)).(*ebiten.Image), nil)
+	default:
+		op := &ebiten.DrawImageOptions{}
+		op.ColorScale.ScaleAlpha(progress)
+		r.DrawImage(transitionTo, op)
+	}
 }
 
-// Update updates the scene for the next draw.
-func (s *SceneManager) Update(_ *Input) error {
+// drawStack draws the top scene of stack, and as many scenes below it as the overlays allow.
+func drawStack(r *ebiten.Image, stack []Scene) {
+	bottom := len(stack) - 1
+	for bottom > 0 {
+		o, ok := stack[bottom].(Overlay)
+		if !ok || !o.DrawsBelow() {
+			break
+		}
+		bottom--
+	}
+
+	for i := max(bottom, 0); i < len(stack); i++ {
+		stack[i].Draw(r)
+	}
+}
+
+// Update updates the scene for the next draw. The top scene gets the input; scenes below it only
+// run if the overlays above them let them, and they get no input.
+func (s *SceneManager) Update(input *Input) error {
 	if s.transitionCount == 0 {
-		return s.current.Update(&State{
+		top := len(s.stack) - 1
+		for i := top; i > 0; i-- {
+			o, ok := s.stack[i].(Overlay)
+			if !ok || !o.UpdatesBelow() {
+				break
+			}
+			if err := s.stack[i-1].Update(&State{
+				SceneManager: s,
+				Input:        &s.noInput,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return s.stack[top].Update(&State{
 			SceneManager: s,
+			Input:        input,
 		})
 	}
 
@@ -66,17 +154,69 @@ func (s *SceneManager) Update(_ *Input) error {
 		return nil
 	}
 
-	s.current = s.next
+	s.stack = s.next
 	s.next = nil
 	return nil
 }
 
-// GoToScene takes us to another scene.
+// GoToScene takes us to another scene, fading over transitionMaxCount ticks. Any scenes that
+// were pushed are dropped.
 func (s *SceneManager) GoToScene(scene Scene) {
-	if s.current == nil {
-		s.current = scene
-	} else {
-		s.next = scene
-		s.transitionCount = transitionMaxCount
+	s.GoToSceneWith(scene, Fade)
+}
+
+// GoToSceneWith takes us to another scene using transition t, dropping any pushed scenes.
+func (s *SceneManager) GoToSceneWith(scene Scene, t Transition) {
+	s.moveTo([]Scene{scene}, t)
+}
+
+// ReplaceScene swaps the top scene for another, using transition t. The scenes below stay put.
+func (s *SceneManager) ReplaceScene(scene Scene, t Transition) {
+	target := s.target()
+	if len(target) == 0 {
+		s.moveTo([]Scene{scene}, t)
+		return
 	}
+
+	next := append([]Scene{}, target[:len(target)-1]...)
+	s.moveTo(append(next, scene), t)
+}
+
+// PushScene puts scene on top of the current one, using transition t.
+func (s *SceneManager) PushScene(scene Scene, t Transition) {
+	next := append([]Scene{}, s.target()...)
+	s.moveTo(append(next, scene), t)
+}
+
+// PopScene removes the top scene, using transition t, uncovering the one below. The last scene
+// is never popped.
+func (s *SceneManager) PopScene(t Transition) {
+	target := s.target()
+	if len(target) < 2 {
+		return
+	}
+	s.moveTo(append([]Scene{}, target[:len(target)-1]...), t)
+}
+
+// target returns the stack we're on, or the one we're moving to if we're mid-transition.
+func (s *SceneManager) target() []Scene {
+	if s.transitionCount > 0 {
+		return s.next
+	}
+	return s.stack
+}
+
+// moveTo starts a transition to stack next. If there's nothing on screen yet, or there's no
+// transition to show, we go straight there.
+func (s *SceneManager) moveTo(next []Scene, t Transition) {
+	if len(s.stack) == 0 || t.Kind == TransitionNone || t.Duration <= 0 {
+		s.stack = next
+		s.next = nil
+		s.transitionCount = 0
+		return
+	}
+
+	s.next = next
+	s.transition = t
+	s.transitionCount = t.Duration
 }
@@ -0,0 +1,241 @@
+package goasteroids
+
+import (
+	"asteroids/internal/atomicfile"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const settingsVersion = 1 // Bump this when the layout of the settings file changes.
+
+// Difficulty changes how hard the game pushes the player.
+type Difficulty int
+
+const (
+	DifficultyNormal Difficulty = iota
+	DifficultyEasy
+	DifficultyHard
+)
+
+// difficultyNames are the names used for difficulties in the settings file, easiest first.
+var difficultyNames = []struct {
+	difficulty Difficulty
+	name       string
+}{
+	{DifficultyEasy, "easy"},
+	{DifficultyNormal, "normal"},
+	{DifficultyHard, "hard"},
+}
+
+// String returns the difficulty's name, as used in the settings file.
+func (d Difficulty) String() string {
+	for _, n := range difficultyNames {
+		if n.difficulty == d {
+			return n.name
+		}
+	}
+	return fmt.Sprintf("Difficulty(%d)", int(d))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Difficulty) MarshalText() ([]byte, error) {
+	for _, n := range difficultyNames {
+		if n.difficulty == d {
+			return []byte(n.name), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown difficulty %d", int(d))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Difficulty) UnmarshalText(text []byte) error {
+	for _, n := range difficultyNames {
+		if n.name == string(text) {
+			*d = n.difficulty
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown difficulty %q", text)
+}
+
+// meteorSpeed scales how fast meteors fly.
+func (d Difficulty) meteorSpeed() float64 {
+	switch d {
+	case DifficultyEasy:
+		return 0.75
+	case DifficultyHard:
+		return 1.25
+	}
+	return 1
+}
+
+// alienAttackScale scales the time between alien attacks.
+func (d Difficulty) alienAttackScale() float64 {
+	switch d {
+	case DifficultyEasy:
+		return 1.5
+	case DifficultyHard:
+		return 0.7
+	}
+	return 1
+}
+
+// alienAimScale scales how far off aliens' aim is, and how long they take to react.
+func (d Difficulty) alienAimScale() float64 {
+	switch d {
+	case DifficultyEasy:
+		return 1.5
+	case DifficultyHard:
+		return 0.6
+	}
+	return 1
+}
+
+// WindowSize is the size of the window when we're not fullscreen.
+type WindowSize struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// windowSizes are the window sizes the options screen offers.
+var windowSizes = []WindowSize{
+	{960, 540},
+	{1280, 720},
+	{1600, 900},
+	{ScreenWidth, ScreenHeight},
+}
+
+// Settings are the player's choices from the options screen.
+type Settings struct {
+	Version     int        `json:"version"`
+	MusicVolume float64    `json:"musicVolume"` // 0 to 1. The heartbeat and the alien's hum.
+	SFXVolume   float64    `json:"sfxVolume"`   // 0 to 1. Everything else.
+	Fullscreen  bool       `json:"fullscreen"`
+	Window      WindowSize `json:"window"`      // The window's size when we're not fullscreen.
+	StarDensity float64    `json:"starDensity"` // Scales the number of stars in the background.
+	Difficulty  Difficulty `json:"difficulty"`
+	LevelSet    string     `json:"levelSet"` // The name of the level set new games are played with.
+}
+
+// DefaultSettings returns the settings the game ships with.
+func DefaultSettings() Settings {
+	return Settings{
+		Version:     settingsVersion,
+		MusicVolume: 1,
+		SFXVolume:   1,
+		Fullscreen:  true,
+		Window:      WindowSize{ScreenWidth, ScreenHeight},
+		StarDensity: 1,
+		Difficulty:  DifficultyNormal,
+		LevelSet:    ClassicLevels,
+	}
+}
+
+var settings = DefaultSettings() // The settings in use.
+
+// settingsPath returns where the settings file lives.
+func settingsPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "settings.json"), nil
+}
+
+// LoadSettings reads the player's settings file. Anything the file doesn't mention keeps its
+// default, and if there's no file at all we just use the defaults.
+func LoadSettings() (Settings, error) {
+	s := DefaultSettings()
+
+	path, err := settingsPath()
+	if err != nil {
+		return s, err
+	}
+
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+
+	// A file we can't read, or one from another version, is set aside rather than lost the next
+	// time we save.
+	if err := json.Unmarshal(contents, &s); err != nil {
+		return DefaultSettings(), fmt.Errorf("reading %s: %w (kept as %s)", path, err, setAsideCorrupt(path))
+	}
+	if s.Version != settingsVersion {
+		return DefaultSettings(), fmt.Errorf("reading %s: unsupported version %d (kept as %s)", path, s.Version, setAsideVersion(path, s.Version))
+	}
+
+	return s.clamped(), nil
+}
+
+// SaveSettings writes s to the player's settings file.
+func SaveSettings(s Settings) error {
+	path, err := settingsPath()
+	if err != nil {
+		return err
+	}
+
+	s.Version = settingsVersion
+	contents, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(path, contents, 0640)
+}
+
+// clamped returns s with anything out of range pulled back into range, so a hand-edited file
+// can't do anything silly.
+func (s Settings) clamped() Settings {
+	s.MusicVolume = min(max(s.MusicVolume, 0), 1)
+	s.SFXVolume = min(max(s.SFXVolume, 0), 1)
+	s.StarDensity = min(max(s.StarDensity, 0), 2)
+	if s.Window.Width < 320 || s.Window.Height < 180 {
+		s.Window = WindowSize{ScreenWidth, ScreenHeight}
+	}
+	if _, err := s.Difficulty.MarshalText(); err != nil {
+		s.Difficulty = DifficultyNormal
+	}
+	if s.LevelSet == "" {
+		s.LevelSet = ClassicLevels
+	}
+	return s
+}
+
+// UseSettings makes s the settings in use, and applies them to the window and the speakers.
+func UseSettings(s Settings) {
+	settings = s.clamped()
+
+	ebiten.SetWindowSize(settings.Window.Width, settings.Window.Height)
+	ebiten.SetFullscreen(settings.Fullscreen)
+	useCursor()
+
+	if speakers != nil {
+		speakers.SetVolume(settings.MusicVolume, settings.SFXVolume)
+	}
+}
+
+// useCursor shows or hides the mouse cursor to suit the settings. The cursor is only in the way
+// when we're fullscreen; in a window the player needs it.
+func useCursor() {
+	if settings.Fullscreen {
+		ebiten.SetCursorMode(ebiten.CursorModeHidden)
+	} else {
+		ebiten.SetCursorMode(ebiten.CursorModeVisible)
+	}
+}
+
+// starCount returns how many stars to draw in the background.
+func starCount() int {
+	return int(numberOfStars * settings.StarDensity)
+}
@@ -0,0 +1,62 @@
+package goasteroids
+
+const splineSteps = 20 // How many straight pieces each stretch of a spline is made from.
+
+// spline is a smooth curve through some points (a Catmull-Rom spline), measured out so that
+// things can be moved along it at a steady speed.
+type spline struct {
+	points  []Vector  // Points close together along the curve, first first.
+	lengths []float64 // How far along the curve each point is.
+}
+
+// newSpline returns the spline that passes through every one of through, in order. It starts at
+// the first and ends at the last.
+func newSpline(through []Vector) *spline {
+	s := &spline{points: []Vector{through[0]}, lengths: []float64{0}}
+
+	// Each stretch bends the way the points either side of it lead. The ends are doubled up, so
+	// the curve starts and finishes heading straight for the next point.
+	at := func(i int) Vector {
+		return through[max(0, min(i, len(through)-1))]
+	}
+	for i := 0; i < len(through)-1; i++ {
+		p0, p1, p2, p3 := at(i-1), at(i), at(i+1), at(i+2)
+		for step := 1; step <= splineSteps; step++ {
+			t := float64(step) / splineSteps
+			p := p1.Scale(2).
+				Add(p2.Sub(p0).Scale(t)).
+				Add(p0.Scale(2).Sub(p1.Scale(5)).Add(p2.Scale(4)).Sub(p3).Scale(t * t)).
+				Add(p1.Scale(3).Sub(p0).Sub(p2.Scale(3)).Add(p3).Scale(t * t * t)).
+				Scale(0.5)
+
+			last := len(s.points) - 1
+			s.lengths = append(s.lengths, s.lengths[last]+s.points[last].Distance(p))
+			s.points = append(s.points, p)
+		}
+	}
+	return s
+}
+
+// length returns how long the curve is.
+func (s *spline) length() float64 {
+	return s.lengths[len(s.lengths)-1]
+}
+
+// at returns the point d along the curve. It's the start for anything before it, and the end for
+// anything past it.
+func (s *spline) at(d float64) Vector {
+	if d <= 0 {
+		return s.points[0]
+	}
+	for i := 1; i < len(s.points); i++ {
+		if d <= s.lengths[i] {
+			piece := s.lengths[i] - s.lengths[i-1]
+			if piece == 0 {
+				return s.points[i]
+			}
+			f := (d - s.lengths[i-1]) / piece
+			return s.points[i-1].Add(s.points[i].Sub(s.points[i-1]).Scale(f))
+		}
+	}
+	return s.points[len(s.points)-1]
+}
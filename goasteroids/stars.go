@@ -15,12 +15,12 @@ type Star struct {
 	brightness float32
 }
 
-func NewStar() *Star {
+func NewStar(rng *rand.Rand) *Star {
 	return &Star{
-		x:          rand.Float32() * ScreenWidth,
-		y:          rand.Float32() * ScreenHeight,
-		r:          rand.Float32() * (3 - 1),
-		brightness: rand.Float32() * 0xff,
+		x:          rng.Float32() * ScreenWidth,
+		y:          rng.Float32() * ScreenHeight,
+		r:          rng.Float32() * (3 - 1),
+		brightness: rng.Float32() * 0xff,
 	}
 }
 
@@ -36,10 +36,10 @@ func (s *Star) Draw(screen *ebiten.Image) {
 
 func (s *Star) Update() {}
 
-func GenerateStars(n int) []*Star {
+func GenerateStars(n int, rng *rand.Rand) []*Star {
 	var stars []*Star
 	for i := 0; i < n; i++ {
-		stars = append(stars, NewStar())
+		stars = append(stars, NewStar(rng))
 	}
 
 	return stars
@@ -0,0 +1,67 @@
+package goasteroids
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+const appDirName = "Go Asteroids" // The name of our folder in the config and data directories.
+
+// configDir returns the folder for the player's settings and controls: $XDG_CONFIG_HOME/Go
+// Asteroids (usually ~/.config) on Linux and the BSDs, Application Support on macOS, and
+// AppData\Roaming on Windows.
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appDirName), nil
+}
+
+// dataDir returns the folder for things the game keeps rather than things the player sets, like
+// high scores and replays. On Linux and the BSDs that's $XDG_DATA_HOME/Go Asteroids (usually
+// ~/.local/share); everywhere else it's the same as configDir.
+func dataDir() (string, error) {
+	switch runtime.GOOS {
+	case "windows", "darwin", "ios", "android", "plan9", "js", "wasip1":
+		return configDir()
+	}
+
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, appDirName), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", appDirName), nil
+}
+
+// setAsideCorrupt moves a file we couldn't make sense of out of the way, so the next save doesn't
+// overwrite it and the player (or we) can still look at it. It returns where the file went.
+func setAsideCorrupt(path string) string {
+	return setAside(path, "corrupt")
+}
+
+// setAsideVersion moves a file written by another version of the game out of the way, so the next
+// save doesn't overwrite it. Going back to that version, the player can rename it back. It returns
+// where the file went.
+func setAsideVersion(path string, version int) string {
+	return setAside(path, fmt.Sprintf("v%d", version))
+}
+
+// setAside renames path to <path>.<why>-<time>, and returns the new name. If it can't be moved,
+// it logs why and returns path.
+func setAside(path, why string) string {
+	aside := fmt.Sprintf("%s.%s-%s", path, why, time.Now().Format("20060102-150405"))
+	if err := os.Rename(path, aside); err != nil {
+		log.Println("Error setting aside", path+":", err)
+		return path
+	}
+	return aside
+}
@@ -8,6 +8,9 @@ var (
 	TagAlien = resolv.NewTag("alien")
 	TagLaser = resolv.NewTag("laser")
 	TagMeteor = resolv.NewTag("meteor")
+	TagMedium = resolv.NewTag("medium")
 	TagSmall = resolv.NewTag("small")
 	TagLarge = resolv.NewTag("large")
+	TagPowerUp = resolv.NewTag("power-up")
+	TagBoss = resolv.NewTag("boss")
 )
\ No newline at end of file
@@ -1,10 +1,10 @@
 package goasteroids
 
-import (
-	"time"
+import "time"
 
-	"github.com/hajimehoshi/ebiten/v2"
-)
+// TicksPerSecond is how many times per second the game is updated. All timers and speeds are worked
+// out from it rather than from ebiten.TPS(), so the game runs the same with or without a window.
+const TicksPerSecond = 60
 
 // Timer is the type for all in-game timers. It holds the current tick count, and the target tick count.
 type Timer struct {
@@ -16,7 +16,7 @@ type Timer struct {
 func NewTimer(d time.Duration) *Timer {
 	return &Timer {
 		currentTicks: 0,
-		targetTicks: int(d.Milliseconds()) * ebiten.TPS() / 1000,
+		targetTicks: int(d.Milliseconds()) * TicksPerSecond / 1000,
 	}
 }
 
@@ -32,6 +32,15 @@ func (t *Timer) IsReady() bool {
 	return t.currentTicks >= t.targetTicks
 }
 
+// Remaining returns how much of the timer is left, from 1 when it's just started to 0 when it's
+// ready.
+func (t *Timer) Remaining() float64 {
+	if t.targetTicks == 0 {
+		return 0
+	}
+	return 1 - float64(t.currentTicks)/float64(t.targetTicks)
+}
+
 // Reset sets a timer back to zero.
 func (t *Timer) Reset() {
 	t.currentTicks = 0
@@ -3,7 +3,6 @@ package goasteroids
 import (
 	"asteroids/assets"
 	"image/color"
-	"log"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
@@ -12,21 +11,29 @@ import (
 
 // TitleScene is the type for our title scene.
 type TitleScene struct {
-	meteors     map[int]*Meteor // A map of meteors.
-	meteorCount int             // How many meteors we currently have in the game.
-	stars       []*Star         // A slice of stars.
+	meteors      map[int]*Meteor // A map of meteors.
+	meteorCount  int             // How many meteors we currently have in the game.
+	stars        []*Star         // A slice of stars.
+	seed         uint64          // The seed for new games, or 0 to pick a new one every time.
+	attractTimer *Timer          // How long until we show the high score table.
 }
 
-var highScore int
-var originalHighScore int
-
-func init() {
-	hs, err := getHighScore()
-	if err != nil {
-		log.Println("Error getting high score:", err)
+// NewTitleScene creates a title scene. Games started from it use seed, or a new seed each time
+// if seed is 0.
+func NewTitleScene(seed uint64) *TitleScene {
+	return &TitleScene{
+		meteors:      make(map[int]*Meteor),
+		stars:        GenerateStars(starCount(), menuRand),
+		seed:         seed,
+		attractTimer: NewTimer(attractTime),
 	}
-	highScore = hs
-	originalHighScore = hs
+}
+
+// startGame starts a game from the title (or the high score table that cycles with it).
+func startGame(state *State, seed uint64) {
+	g := NewGameScene(seedOrNew(seed))
+	g.fixSeed(seed != 0)
+	state.SceneManager.GoToScene(g)
 }
 
 // Draw draws all elements on the screen. It's called once per frame.
@@ -42,7 +49,7 @@ func (t *TitleScene) Draw(screen *ebiten.Image) {
 	}
 
 	// Draw text.
-	textToDraw := "Press space to play"
+	textToDraw := "Press space or start to play"
 
 	op := &text.DrawOptions{
 		LayoutOptions: text.LayoutOptions{
@@ -56,20 +63,44 @@ func (t *TitleScene) Draw(screen *ebiten.Image) {
 		Size:   48,
 	}, op)
 
-
+	op = &text.DrawOptions{
+		LayoutOptions: text.LayoutOptions{
+			PrimaryAlign: text.AlignCenter,
+		},
+	}
+	op.ColorScale.ScaleWithColor(color.White)
+	op.ColorScale.ScaleAlpha(0.5)
+	op.GeoM.Translate(float64(ScreenWidth/2), ScreenHeight-120)
+	text.Draw(screen, "O for options   L for high scores", &text.GoTextFace{
+		Source: assets.ScoreFont,
+		Size:   16,
+	}, op)
 }
 
 // Update updates all game scene elements for the next draw. It's called once per tick.
 func (t *TitleScene) Update(state *State) error {
-	// Check for a spacebar press.
-	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-		state.SceneManager.GoToScene(NewGameScene())
+	// Check for a spacebar (or gamepad A/Start) press.
+	if state.Input.IsJustPressed(ActionConfirm) {
+		startGame(state, t.seed)
+		return nil
+	}
+
+	// Show the high score table when asked, or when nobody's played for a while.
+	t.attractTimer.Update()
+	if inpututil.IsKeyJustPressed(ebiten.KeyL) || t.attractTimer.IsReady() {
+		refreshGlobalScores() // So it's ready by the time it's shown.
+		state.SceneManager.GoToScene(NewLeaderboardScene(t.seed, -1))
+		return nil
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyO) {
+		state.SceneManager.PushScene(NewOptionsScene(), pauseTransition)
 		return nil
 	}
 
 	// Draw meteors, if appropriate.
 	if len(t.meteors) < 10 {
-		m := NewMeteor(0.25, &GameScene{}, len(t.meteors)-1)
+		m := NewMeteor(0.25, EdgeAny, &GameScene{}, len(t.meteors)-1, menuRand)
 		t.meteorCount++
 		t.meteors[t.meteorCount] = m
 	}
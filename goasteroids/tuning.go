@@ -0,0 +1,418 @@
+package goasteroids
+
+import (
+	"asteroids/assets"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const tuningVersion = 1 // Bump this when the layout of the tuning file changes.
+
+// Duration is a time.Duration that's written as "150ms" or "6s" in JSON, which is easier on the
+// designers than nanoseconds.
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Tuning is every number that decides how the game plays. The defaults are in
+// assets/data/tuning.json, and a tuning.json in the config directory can override any of them.
+type Tuning struct {
+	Version int `json:"version"`
+
+	Player struct {
+		RotationPerSecond  float64  `json:"rotationPerSecond"`  // Radians.
+		MaxAcceleration    float64  `json:"maxAcceleration"`    // The ship's top speed, in pixels per tick.
+		LaserSpeed         float64  `json:"laserSpeed"`         // Pixels per second.
+		ShootCoolDown      Duration `json:"shootCoolDown"`      // The pause between shots in a burst.
+		BurstCoolDown      Duration `json:"burstCoolDown"`      // The pause after a burst.
+		MaxShotsPerBurst   int      `json:"maxShotsPerBurst"`   // How many shots make a burst.
+		Lives              int      `json:"lives"`              // Lives at the start of a game.
+		MaxLives           int      `json:"maxLives"`           // Extra lives stop coming once the player has this many.
+		Shields            int      `json:"shields"`            // Shields at the start of a game.
+		ShieldDuration     Duration `json:"shieldDuration"`     // How long a shield lasts.
+		HyperspaceCooldown Duration `json:"hyperspaceCooldown"` // The wait between hyperspace jumps.
+		DriftTime          Duration `json:"driftTime"`          // How long the ship drifts after letting go of thrust.
+		DyingAnimation     Duration `json:"dyingAnimation"`     // How long each frame of the explosion shows.
+	} `json:"player"`
+
+	Meteors struct {
+		BaseVelocity   float64  `json:"baseVelocity"`   // The speed meteors start each level at.
+		SpawnTime      Duration `json:"spawnTime"`      // The wait between new meteors.
+		SpeedUpAmount  float64  `json:"speedUpAmount"`  // How much faster meteors get each speed up.
+		SpeedUpTime    Duration `json:"speedUpTime"`    // The wait between speed ups.
+		PiecesPerSplit int      `json:"piecesPerSplit"` // How many pieces a large or medium meteor breaks into.
+		SplitSpread    float64  `json:"splitSpread"`    // Degrees the pieces fan out over, heading away from the shot.
+		SplitKick      float64  `json:"splitKick"`      // The speed the pieces get on top of their parent's.
+		ScoreLarge     int      `json:"scoreLarge"`     // Points for hitting a large meteor.
+		ScoreMedium    int      `json:"scoreMedium"`    // Points for hitting a medium meteor.
+		ScoreSmall     int      `json:"scoreSmall"`     // Points for hitting a small meteor.
+	} `json:"meteors"`
+
+	Materials struct {
+		RockScore       int     `json:"rockScore"`       // A rock meteor's points are multiplied by this.
+		IronScore       int     `json:"ironScore"`       // An iron meteor's points are multiplied by this.
+		IronHits        int     `json:"ironHits"`        // Hits a large iron meteor takes to break, one fewer for each size down.
+		IceScore        int     `json:"iceScore"`        // An ice meteor's points are multiplied by this.
+		IceShards       int     `json:"iceShards"`       // How many shards an ice meteor shatters into.
+		IceShardSpeed   float64 `json:"iceShardSpeed"`   // How fast the shards fly off, on top of their parent's speed.
+		ExplosiveScore  int     `json:"explosiveScore"`  // An explosive meteor's points are multiplied by this.
+		ExplosiveRadius float64 `json:"explosiveRadius"` // How far a large explosive meteor's blast reaches. Smaller ones reach less far.
+	} `json:"materials"`
+
+	PowerUps struct {
+		MeteorDropChance  int      `json:"meteorDropChance"`  // The chance in 100 of a broken meteor dropping a power-up.
+		AlienDropChance   int      `json:"alienDropChance"`   // The chance in 100 of a shot down alien dropping a power-up.
+		Lifetime          Duration `json:"lifetime"`          // How long a power-up drifts about before it's gone.
+		DriftSpeed        float64  `json:"driftSpeed"`        // How fast power-ups drift, in pixels per tick.
+		EffectTime        Duration `json:"effectTime"`        // How long rapid fire, spread shot and the multiplier last.
+		RapidFireCoolDown Duration `json:"rapidFireCoolDown"` // The pause between shots with rapid fire.
+		SpreadAngle       float64  `json:"spreadAngle"`       // Degrees between the lasers of a spread shot.
+		Multiplier        int      `json:"multiplier"`        // Points are multiplied by this while the multiplier lasts.
+	} `json:"powerUps"`
+
+	Weapons struct {
+		Loadout          []WeaponKind `json:"loadout"`          // The weapons the ship carries, in the order they're switched between.
+		SpreadCoolDown   Duration     `json:"spreadCoolDown"`   // The pause between spread shots.
+		SpreadShots      int          `json:"spreadShots"`      // How many lasers a spread shot fires.
+		SpreadAngle      float64      `json:"spreadAngle"`      // Degrees the lasers of a spread shot fan out over.
+		BeamChargeTime   Duration     `json:"beamChargeTime"`   // How long the beam takes to charge fully.
+		BeamCoolDown     Duration     `json:"beamCoolDown"`     // The pause after a beam before it can charge again.
+		BeamLength       float64      `json:"beamLength"`       // How long a fully charged beam is.
+		BeamWidth        float64      `json:"beamWidth"`        // How wide a fully charged beam is.
+		BeamTime         Duration     `json:"beamTime"`         // How long a beam lasts.
+		MissileCoolDown  Duration     `json:"missileCoolDown"`  // The pause between missiles.
+		MissileSpeed     float64      `json:"missileSpeed"`     // Pixels per second.
+		MissileTurnRate  float64      `json:"missileTurnRate"`  // Degrees per second a missile can turn.
+		MissileFuel      Duration     `json:"missileFuel"`      // How long a missile can steer for, after which it flies straight.
+		MissileLifetime  Duration     `json:"missileLifetime"`  // How long a missile flies before it burns out.
+		MissileLockCone  float64      `json:"missileLockCone"`  // Degrees across the cone in front that missiles lock on within.
+		MissileLockRange float64      `json:"missileLockRange"` // How far away missiles can lock on to something.
+		MineCoolDown     Duration     `json:"mineCoolDown"`     // The pause between mines.
+		MineArmTime      Duration     `json:"mineArmTime"`      // How long after it's dropped a mine is armed.
+		MineLifetime     Duration     `json:"mineLifetime"`     // How long a mine lasts before it fizzles out.
+		MineRadius       float64      `json:"mineRadius"`       // How far a mine's blast reaches.
+		MaxMines         int          `json:"maxMines"`         // How many mines can be about at once.
+	} `json:"weapons"`
+
+	Aliens struct {
+		BaseVelocity float64  `json:"baseVelocity"`
+		SpawnTime    Duration `json:"spawnTime"`  // The wait between aliens.
+		AttackTime   Duration `json:"attackTime"` // The wait between alien shots.
+		LaserSpeed   float64  `json:"laserSpeed"` // Pixels per second.
+
+		// How aliens that steer (snipers, kamikazes and dodgers) fly.
+		SteeringSpeed float64  `json:"steeringSpeed"` // Pixels per second.
+		KamikazeSpeed float64  `json:"kamikazeSpeed"` // Pixels per second, for kamikazes.
+		Agility       float64  `json:"agility"`       // How quickly they can change velocity, in pixels per second per second.
+		SniperRange   float64  `json:"sniperRange"`   // How far from the ship snipers keep.
+		DodgerRange   float64  `json:"dodgerRange"`   // How far from the ship dodgers keep.
+		AvoidRange    float64  `json:"avoidRange"`    // How close a meteor gets before they steer away from it.
+		EvadeRange    float64  `json:"evadeRange"`    // How close a laser gets before dodgers get out of its way.
+		StayTime      Duration `json:"stayTime"`      // How long they stay before they leave.
+
+		// How well aliens that aim at the ship aim. They start at AimError and ReactionTime, and get
+		// better level by level until they're at their deadliest on DeadliestLevel. The difficulty
+		// scales them.
+		AimError              float64  `json:"aimError"`              // Degrees either side a shot can be off by.
+		ReactionTime          Duration `json:"reactionTime"`          // How long it takes them to see what the ship's doing.
+		DeadliestAimError     float64  `json:"deadliestAimError"`     // The aim error at their deadliest.
+		DeadliestReactionTime Duration `json:"deadliestReactionTime"` // The reaction time at their deadliest.
+		DeadliestLevel        int      `json:"deadliestLevel"`        // The level they're at their deadliest on.
+	} `json:"aliens"`
+
+	// The mothership that turns up instead of meteors every so many levels.
+	Boss struct {
+		Every        int      `json:"every"`        // A boss every this many levels.
+		CoreHealth   int      `json:"coreHealth"`   // How many hits the core takes.
+		TurretHealth int      `json:"turretHealth"` // How many hits each turret takes.
+		Speed        float64  `json:"speed"`        // Pixels per second it sweeps across the screen.
+		FanTime      Duration `json:"fanTime"`      // The wait between fans of lasers.
+		FanShots     int      `json:"fanShots"`     // How many lasers are in a fan.
+		FanAngle     float64  `json:"fanAngle"`     // Degrees across a fan.
+		MinionTime   Duration `json:"minionTime"`   // The wait between minions, once it's launching them.
+		MaxMinions   int      `json:"maxMinions"`   // How many minions can be about at once.
+		SummonTime   Duration `json:"summonTime"`   // The wait between meteors, once it's summoning them.
+		MaxMeteors   int      `json:"maxMeteors"`   // How many meteors can be about at once.
+		TurretPoints int      `json:"turretPoints"` // Points for knocking out a turret.
+		VictoryBonus int      `json:"victoryBonus"` // Points for destroying it.
+	} `json:"boss"`
+
+	Levels struct {
+		StartTime      Duration `json:"startTime"`      // The pause between levels.
+		BaseBeatWait   Duration `json:"baseBeatWait"`   // The gap between heartbeats at the start of a level.
+		MinBeatWait    Duration `json:"minBeatWait"`    // The heartbeat never gets quicker than this.
+		BeatSpeedUp    Duration `json:"beatSpeedUp"`    // How much quicker the heartbeat gets each beat.
+		ExtraLifeEvery int      `json:"extraLifeEvery"` // An extra life every this many levels.
+	} `json:"levels"`
+}
+
+var tuning = mustDefaultTuning() // The tuning new games are played with.
+
+// mustDefaultTuning returns the tuning the game ships with. A broken default file is a bug, so it
+// panics, like a missing asset does.
+func mustDefaultTuning() *Tuning {
+	t, err := parseTuning(&Tuning{}, assets.TuningData)
+	if err != nil {
+		panic(fmt.Sprintf("default tuning: %v", err))
+	}
+	return t
+}
+
+// DefaultTuning returns a copy of the tuning the game ships with.
+func DefaultTuning() *Tuning {
+	return mustDefaultTuning()
+}
+
+// parseTuning reads data over a copy of base, so a file only has to mention what it changes, and
+// checks the result.
+func parseTuning(base *Tuning, data []byte) (*Tuning, error) {
+	t := *base
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields() // A typo shouldn't silently leave a value at its default.
+	if err := d.Decode(&t); err != nil {
+		return nil, err
+	}
+	if t.Version != tuningVersion {
+		return nil, fmt.Errorf("unsupported version %d", t.Version)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Validate checks that every value makes sense, and says which one doesn't if it doesn't.
+func (t *Tuning) Validate() error {
+	var errs []error
+	positive := func(name string, v float64) {
+		if v <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be more than 0, not %v", name, v))
+		}
+	}
+	notNegative := func(name string, v float64) {
+		if v < 0 {
+			errs = append(errs, fmt.Errorf("%s can't be negative, not %v", name, v))
+		}
+	}
+	chance := func(name string, v int) {
+		if v < 0 || v > 100 {
+			errs = append(errs, fmt.Errorf("%s must be from 0 to 100, not %d", name, v))
+		}
+	}
+	atLeastATick := func(name string, d Duration) {
+		if time.Duration(d) < time.Second/TicksPerSecond {
+			errs = append(errs, fmt.Errorf("%s must be at least one tick (%v), not %v", name, time.Second/TicksPerSecond, time.Duration(d)))
+		}
+	}
+
+	p := t.Player
+	positive("player.rotationPerSecond", p.RotationPerSecond)
+	positive("player.maxAcceleration", p.MaxAcceleration)
+	positive("player.laserSpeed", p.LaserSpeed)
+	atLeastATick("player.shootCoolDown", p.ShootCoolDown)
+	atLeastATick("player.burstCoolDown", p.BurstCoolDown)
+	positive("player.maxShotsPerBurst", float64(p.MaxShotsPerBurst))
+	positive("player.lives", float64(p.Lives))
+	if p.MaxLives < p.Lives {
+		errs = append(errs, fmt.Errorf("player.maxLives (%d) can't be less than player.lives (%d)", p.MaxLives, p.Lives))
+	}
+	notNegative("player.shields", float64(p.Shields))
+	atLeastATick("player.shieldDuration", p.ShieldDuration)
+	atLeastATick("player.hyperspaceCooldown", p.HyperspaceCooldown)
+	atLeastATick("player.driftTime", p.DriftTime)
+	atLeastATick("player.dyingAnimation", p.DyingAnimation)
+
+	m := t.Meteors
+	positive("meteors.baseVelocity", m.BaseVelocity)
+	atLeastATick("meteors.spawnTime", m.SpawnTime)
+	notNegative("meteors.speedUpAmount", m.SpeedUpAmount)
+	atLeastATick("meteors.speedUpTime", m.SpeedUpTime)
+	positive("meteors.piecesPerSplit", float64(m.PiecesPerSplit))
+	notNegative("meteors.splitSpread", m.SplitSpread)
+	notNegative("meteors.splitKick", m.SplitKick)
+	notNegative("meteors.scoreLarge", float64(m.ScoreLarge))
+	notNegative("meteors.scoreMedium", float64(m.ScoreMedium))
+	notNegative("meteors.scoreSmall", float64(m.ScoreSmall))
+
+	mt := t.Materials
+	notNegative("materials.rockScore", float64(mt.RockScore))
+	notNegative("materials.ironScore", float64(mt.IronScore))
+	positive("materials.ironHits", float64(mt.IronHits))
+	notNegative("materials.iceScore", float64(mt.IceScore))
+	notNegative("materials.iceShards", float64(mt.IceShards))
+	notNegative("materials.iceShardSpeed", mt.IceShardSpeed)
+	notNegative("materials.explosiveScore", float64(mt.ExplosiveScore))
+	notNegative("materials.explosiveRadius", mt.ExplosiveRadius)
+
+	pu := t.PowerUps
+	chance("powerUps.meteorDropChance", pu.MeteorDropChance)
+	chance("powerUps.alienDropChance", pu.AlienDropChance)
+	atLeastATick("powerUps.lifetime", pu.Lifetime)
+	notNegative("powerUps.driftSpeed", pu.DriftSpeed)
+	atLeastATick("powerUps.effectTime", pu.EffectTime)
+	atLeastATick("powerUps.rapidFireCoolDown", pu.RapidFireCoolDown)
+	notNegative("powerUps.spreadAngle", pu.SpreadAngle)
+	positive("powerUps.multiplier", float64(pu.Multiplier))
+
+	w := t.Weapons
+	if len(w.Loadout) == 0 {
+		errs = append(errs, errors.New("weapons.loadout: the ship needs at least one weapon"))
+	}
+	for i, kind := range w.Loadout {
+		if _, ok := weaponNames[kind]; !ok {
+			errs = append(errs, fmt.Errorf("weapons.loadout[%d]: unknown weapon %q", i, kind))
+		}
+	}
+	atLeastATick("weapons.spreadCoolDown", w.SpreadCoolDown)
+	positive("weapons.spreadShots", float64(w.SpreadShots))
+	notNegative("weapons.spreadAngle", w.SpreadAngle)
+	atLeastATick("weapons.beamChargeTime", w.BeamChargeTime)
+	atLeastATick("weapons.beamCoolDown", w.BeamCoolDown)
+	positive("weapons.beamLength", w.BeamLength)
+	positive("weapons.beamWidth", w.BeamWidth)
+	atLeastATick("weapons.beamTime", w.BeamTime)
+	atLeastATick("weapons.missileCoolDown", w.MissileCoolDown)
+	positive("weapons.missileSpeed", w.MissileSpeed)
+	notNegative("weapons.missileTurnRate", w.MissileTurnRate)
+	atLeastATick("weapons.missileFuel", w.MissileFuel)
+	atLeastATick("weapons.missileLifetime", w.MissileLifetime)
+	positive("weapons.missileLockCone", w.MissileLockCone)
+	positive("weapons.missileLockRange", w.MissileLockRange)
+	atLeastATick("weapons.mineCoolDown", w.MineCoolDown)
+	notNegative("weapons.mineArmTime", float64(w.MineArmTime))
+	atLeastATick("weapons.mineLifetime", w.MineLifetime)
+	positive("weapons.mineRadius", w.MineRadius)
+	positive("weapons.maxMines", float64(w.MaxMines))
+
+	a := t.Aliens
+	positive("aliens.baseVelocity", a.BaseVelocity)
+	atLeastATick("aliens.spawnTime", a.SpawnTime)
+	atLeastATick("aliens.attackTime", a.AttackTime)
+	positive("aliens.laserSpeed", a.LaserSpeed)
+	positive("aliens.steeringSpeed", a.SteeringSpeed)
+	positive("aliens.kamikazeSpeed", a.KamikazeSpeed)
+	positive("aliens.agility", a.Agility)
+	positive("aliens.sniperRange", a.SniperRange)
+	positive("aliens.dodgerRange", a.DodgerRange)
+	notNegative("aliens.avoidRange", a.AvoidRange)
+	notNegative("aliens.evadeRange", a.EvadeRange)
+	atLeastATick("aliens.stayTime", a.StayTime)
+	notNegative("aliens.aimError", a.AimError)
+	notNegative("aliens.reactionTime", float64(a.ReactionTime))
+	notNegative("aliens.deadliestAimError", a.DeadliestAimError)
+	notNegative("aliens.deadliestReactionTime", float64(a.DeadliestReactionTime))
+	positive("aliens.deadliestLevel", float64(a.DeadliestLevel))
+
+	b := t.Boss
+	positive("boss.every", float64(b.Every))
+	positive("boss.coreHealth", float64(b.CoreHealth))
+	positive("boss.turretHealth", float64(b.TurretHealth))
+	positive("boss.speed", b.Speed)
+	atLeastATick("boss.fanTime", b.FanTime)
+	positive("boss.fanShots", float64(b.FanShots))
+	notNegative("boss.fanAngle", b.FanAngle)
+	atLeastATick("boss.minionTime", b.MinionTime)
+	positive("boss.maxMinions", float64(b.MaxMinions))
+	atLeastATick("boss.summonTime", b.SummonTime)
+	positive("boss.maxMeteors", float64(b.MaxMeteors))
+	notNegative("boss.turretPoints", float64(b.TurretPoints))
+	notNegative("boss.victoryBonus", float64(b.VictoryBonus))
+
+	l := t.Levels
+	atLeastATick("levels.startTime", l.StartTime)
+	atLeastATick("levels.minBeatWait", l.MinBeatWait)
+	if l.BaseBeatWait < l.MinBeatWait {
+		errs = append(errs, fmt.Errorf("levels.baseBeatWait (%v) can't be less than levels.minBeatWait (%v)", time.Duration(l.BaseBeatWait), time.Duration(l.MinBeatWait)))
+	}
+	notNegative("levels.beatSpeedUp", float64(l.BeatSpeedUp))
+	positive("levels.extraLifeEvery", float64(l.ExtraLifeEvery))
+
+	return errors.Join(errs...)
+}
+
+// Fingerprint identifies the tuning, so a replay can tell whether it's being played back with
+// the numbers it was recorded with.
+func (t *Tuning) Fingerprint() uint64 {
+	b, _ := json.Marshal(t)
+	h := fnv.New64a()
+	h.Write(b)
+	return h.Sum64()
+}
+
+// tuningPath returns where the designers' tuning file lives.
+func tuningPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "tuning.json"), nil
+}
+
+// LoadTuning reads the tuning file at path (or tuning.json in the config directory, if path is
+// "") over the defaults, and checks it. If there's no file, it's just the defaults. On any error
+// the defaults are returned along with it.
+func LoadTuning(path string) (*Tuning, error) {
+	defaults := DefaultTuning()
+
+	if path == "" {
+		var err error
+		if path, err = tuningPath(); err != nil {
+			return defaults, err
+		}
+	}
+
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return defaults, nil
+	}
+	if err != nil {
+		return defaults, err
+	}
+
+	t, err := parseTuning(defaults, contents)
+	if err != nil {
+		return defaults, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return t, nil
+}
+
+var tuningFile string // The tuning file UseTuning was given, so it can be reloaded.
+
+// UseTuning loads the tuning from path (see LoadTuning) and plays new games with it.
+func UseTuning(path string) error {
+	tuningFile = path
+	t, err := LoadTuning(path)
+	tuning = t
+	return err
+}
+
+// ReloadTuning reads the tuning file again. If it's broken, the tuning in use is kept. A game in
+// progress picks the new numbers up straight away (and its replay no longer plays back).
+func ReloadTuning() error {
+	t, err := LoadTuning(tuningFile)
+	if err != nil {
+		return err
+	}
+	tuning = t
+	return nil
+}
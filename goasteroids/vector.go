@@ -3,11 +3,76 @@ package goasteroids
 import "math"
 
 type Vector struct {
-	X float64
-	Y float64
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
 }
 
 func (v Vector) Normalize() Vector {
 	magnitude := math.Sqrt(v.X*v.X + v.Y*v.Y)
 	return Vector{v.X / magnitude, v.Y / magnitude}
-}
\ No newline at end of file
+}
+
+// Distance returns how far v is from to.
+func (v Vector) Distance(to Vector) float64 {
+	return math.Hypot(to.X-v.X, to.Y-v.Y)
+}
+
+// Add returns v plus o.
+func (v Vector) Add(o Vector) Vector {
+	return Vector{v.X + o.X, v.Y + o.Y}
+}
+
+// Sub returns v take away o: the way from o to v.
+func (v Vector) Sub(o Vector) Vector {
+	return Vector{v.X - o.X, v.Y - o.Y}
+}
+
+// Rotation returns the way v points, as a rotation like the ship's: clockwise from straight up,
+// in radians.
+func (v Vector) Rotation() float64 {
+	return math.Atan2(v.X, -v.Y)
+}
+
+// InCone reports whether to is within halfAngle radians either side of rotation, looking from v.
+func (v Vector) InCone(to Vector, rotation, halfAngle float64) bool {
+	return math.Abs(math.Remainder(to.Sub(v).Rotation()-rotation, 2*math.Pi)) <= halfAngle
+}
+
+// heading returns a vector length long, pointing the way rotation does.
+func heading(rotation, length float64) Vector {
+	return Vector{math.Sin(rotation) * length, -math.Cos(rotation) * length}
+}
+
+// turnTowards returns rotation turned towards want, the short way round, by no more than limit.
+func turnTowards(rotation, want, limit float64) float64 {
+	turn := math.Remainder(want-rotation, 2*math.Pi)
+	return rotation + max(-limit, min(limit, turn))
+}
+
+// Scale returns v, f times as long.
+func (v Vector) Scale(f float64) Vector {
+	return Vector{v.X * f, v.Y * f}
+}
+
+// Length returns how long v is.
+func (v Vector) Length() float64 {
+	return math.Hypot(v.X, v.Y)
+}
+
+// Limit returns v, cut down to max long if it's longer.
+func (v Vector) Limit(max float64) Vector {
+	if l := v.Length(); l > max {
+		return v.Scale(max / l)
+	}
+	return v
+}
+
+// Towards returns the vector length long pointing from v to to. It's zero if they're the same.
+func (v Vector) Towards(to Vector, length float64) Vector {
+	d := to.Sub(v)
+	l := d.Length()
+	if l == 0 {
+		return Vector{}
+	}
+	return d.Scale(length / l)
+}
@@ -0,0 +1,97 @@
+package goasteroids
+
+import (
+	"math"
+	"testing"
+)
+
+// closeTo reports whether a and b are the same, give or take rounding.
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestTurnTowards(t *testing.T) {
+	tests := []struct {
+		name                  string
+		rotation, want, limit float64
+		expect                float64
+	}{
+		{"within the limit", 0, 0.1, 0.5, 0.1},
+		{"clamped clockwise", 0, 2, 0.5, 0.5},
+		{"clamped anticlockwise", 0, -2, 0.5, -0.5},
+		{"already there", 1, 1, 0.5, 1},
+		{"can't turn at all", 0, 1, 0, 0},
+		{"the short way across the bottom", 3, -3, 0.5, 3 + (2*math.Pi - 6)},
+		{"the short way back", -3, 3, 0.1, -3.1},
+		{"a whole turn is no turn", 0, 2 * math.Pi, 0.5, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := turnTowards(tt.rotation, tt.want, tt.limit); !closeTo(got, tt.expect) {
+				t.Errorf("turnTowards(%v, %v, %v) = %v, want %v", tt.rotation, tt.want, tt.limit, got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestRotationMatchesHeading(t *testing.T) {
+	tests := []struct {
+		name     string
+		v        Vector
+		rotation float64
+	}{
+		{"up", Vector{0, -1}, 0},
+		{"right", Vector{1, 0}, math.Pi / 2},
+		{"down", Vector{0, 1}, math.Pi},
+		{"left", Vector{-1, 0}, -math.Pi / 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.v.Rotation(); !closeTo(got, tt.rotation) {
+				t.Errorf("%v.Rotation() = %v, want %v", tt.v, got, tt.rotation)
+			}
+			if h := heading(tt.rotation, 2); !closeTo(h.X, tt.v.X*2) || !closeTo(h.Y, tt.v.Y*2) {
+				t.Errorf("heading(%v, 2) = %v, want %v", tt.rotation, h, tt.v.Scale(2))
+			}
+		})
+	}
+}
+
+func TestInCone(t *testing.T) {
+	from := Vector{100, 100}
+	tests := []struct {
+		name      string
+		to        Vector
+		rotation  float64
+		halfAngle float64
+		want      bool
+	}{
+		{"dead ahead", Vector{100, 0}, 0, 0.1, true},
+		{"behind", Vector{100, 200}, 0, math.Pi / 4, false},
+		{"on the edge", Vector{200, 0}, 0, math.Pi / 4, true},
+		{"just outside", Vector{200, 0}, 0, math.Pi/4 - 0.01, false},
+		{"across the bottom", Vector{99, 200}, math.Pi, 0.1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := from.InCone(tt.to, tt.rotation, tt.halfAngle); got != tt.want {
+				t.Errorf("InCone(%v, %v, %v) = %v, want %v", tt.to, tt.rotation, tt.halfAngle, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitAndTowards(t *testing.T) {
+	if got := (Vector{3, 4}).Limit(10); got != (Vector{3, 4}) {
+		t.Errorf("a short vector was cut down to %v", got)
+	}
+	if got := (Vector{3, 4}).Limit(1); !closeTo(got.X, 0.6) || !closeTo(got.Y, 0.8) {
+		t.Errorf("Limit(1) = %v, want {0.6 0.8}", got)
+	}
+	if got := (Vector{1, 1}).Towards(Vector{1, 5}, 2); got != (Vector{0, 2}) {
+		t.Errorf("Towards = %v, want {0 2}", got)
+	}
+	if got := (Vector{1, 1}).Towards(Vector{1, 1}, 2); got != (Vector{}) {
+		t.Errorf("Towards itself = %v, want nothing", got)
+	}
+}
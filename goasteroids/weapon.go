@@ -0,0 +1,334 @@
+package goasteroids
+
+import (
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/solarlune/resolv"
+)
+
+const reticleRadius = 30 // How big the lock-on reticle is, when what it's on isn't a circle.
+
+// WeaponKind names a weapon, in the tuning's loadout.
+type WeaponKind string
+
+const (
+	WeaponBurstLaser WeaponKind = "burst-laser" // The classic laser: a few quick shots, then a pause.
+	WeaponSpreadShot WeaponKind = "spread-shot" // A fan of lasers.
+	WeaponBeam       WeaponKind = "beam"        // Hold fire to charge, let go to fire a beam that goes through everything.
+	WeaponMissiles   WeaponKind = "missiles"    // Missiles that home in on meteors and aliens.
+	WeaponMines      WeaponKind = "mines"       // Mines that sit where they're dropped, and blow up whatever touches them.
+)
+
+// weaponNames are what each weapon is called in the HUD.
+var weaponNames = map[WeaponKind]string{
+	WeaponBurstLaser: "BURST LASER",
+	WeaponSpreadShot: "SPREAD SHOT",
+	WeaponBeam:       "BEAM",
+	WeaponMissiles:   "MISSILES",
+	WeaponMines:      "MINES",
+}
+
+// Weapon is something the ship carries and shoots with. Each weapon looks after its own cooldown,
+// what it shoots and how it sounds.
+type Weapon interface {
+	// Kind says which weapon it is.
+	Kind() WeaponKind
+	// Update is called once per tick while the weapon's in use, with whether fire is held down. It
+	// fires from p when it's ready.
+	Update(p *Player, firing bool)
+	// Holster is called when the ship switches to another weapon, so it can let go of anything
+	// half done.
+	Holster()
+	// Draw draws anything the weapon shows over the game, like how charged it is.
+	Draw(screen *ebiten.Image, p *Player)
+}
+
+// Projectile is anything the player's weapons shoot. GameScene moves them, checks what they hit,
+// and gets rid of them once they're spent.
+type Projectile interface {
+	Update()
+	Draw(screen *ebiten.Image)
+	shape() resolv.IShape             // The collision shape.
+	point() Vector                    // Where it is, for knocking what it hits away from.
+	strike(target resolv.IShape) bool // Reports whether it hits target now. It uses itself up if it should.
+	isSpent() bool                    // Reports whether it's hit something, burned out or left the screen.
+}
+
+// newWeapon is a factory method which creates a weapon of kind, with the numbers from tuning t.
+func newWeapon(kind WeaponKind, t *Tuning) Weapon {
+	switch kind {
+	case WeaponSpreadShot:
+		return NewSpreadShot(t)
+	case WeaponBeam:
+		return NewBeamCannon(t)
+	case WeaponMissiles:
+		return NewMissileLauncher(t)
+	case WeaponMines:
+		return NewMineLayer(t)
+	default:
+		return NewBurstLaser(t)
+	}
+}
+
+// BurstLaser is the classic laser: a few shots, then a pause before the next burst. Rapid fire
+// shoots quicker with no pause, and the spread shot power-up adds a laser either side.
+type BurstLaser struct {
+	shootCoolDown     *Timer // Pause between shots.
+	burstCoolDown     *Timer // Pause between bursts of shots.
+	rapidFireCoolDown *Timer // Pause between shots with rapid fire.
+	shotsFired        int    // A counter to keep track of max shots per burst.
+}
+
+// NewBurstLaser is a factory method which creates a burst laser.
+func NewBurstLaser(t *Tuning) *BurstLaser {
+	return &BurstLaser{
+		shootCoolDown:     NewTimer(time.Duration(t.Player.ShootCoolDown)),
+		burstCoolDown:     NewTimer(time.Duration(t.Player.BurstCoolDown)),
+		rapidFireCoolDown: NewTimer(time.Duration(t.PowerUps.RapidFireCoolDown)),
+	}
+}
+
+func (w *BurstLaser) Kind() WeaponKind { return WeaponBurstLaser }
+
+func (w *BurstLaser) Update(p *Player, firing bool) {
+	w.burstCoolDown.Update()
+	w.shootCoolDown.Update()
+	w.rapidFireCoolDown.Update()
+
+	rapidFire := p.effects.has(PowerUpRapidFire)
+	coolDown := w.shootCoolDown
+	if rapidFire {
+		coolDown = w.rapidFireCoolDown
+	}
+
+	if w.burstCoolDown.IsReady() {
+		if coolDown.IsReady() && firing {
+			coolDown.Reset()
+			w.shotsFired++
+			if w.shotsFired <= p.game.tuning.Player.MaxShotsPerBurst || rapidFire {
+				p.fireLaser(p.rotation)
+				if p.effects.has(PowerUpSpreadShot) {
+					spread := p.game.tuning.PowerUps.SpreadAngle * math.Pi / 180
+					p.fireLaser(p.rotation - spread)
+					p.fireLaser(p.rotation + spread)
+				}
+
+				switch (w.shotsFired-1)%3 + 1 {
+				case 1:
+					p.game.audio.Play(SoundLaserOne)
+				case 2:
+					p.game.audio.Play(SoundLaserTwo)
+				case 3:
+					p.game.audio.Play(SoundLaserThree)
+				}
+			} else {
+				w.burstCoolDown.Reset()
+				w.shotsFired = 0
+			}
+		}
+	}
+}
+
+func (w *BurstLaser) Holster() {}
+
+func (w *BurstLaser) Draw(*ebiten.Image, *Player) {}
+
+// SpreadShot fires a fan of lasers, with a longer pause between shots.
+type SpreadShot struct {
+	coolDown *Timer // Pause between shots.
+	sound    Sound  // What a shot sounds like.
+}
+
+// NewSpreadShot is a factory method which creates a spread shot.
+func NewSpreadShot(t *Tuning) *SpreadShot {
+	return &SpreadShot{
+		coolDown: NewTimer(time.Duration(t.Weapons.SpreadCoolDown)),
+		sound:    SoundLaserTwo,
+	}
+}
+
+func (w *SpreadShot) Kind() WeaponKind { return WeaponSpreadShot }
+
+func (w *SpreadShot) Update(p *Player, firing bool) {
+	w.coolDown.Update()
+	if !firing || !w.coolDown.IsReady() {
+		return
+	}
+	w.coolDown.Reset()
+
+	// Fan the lasers out evenly, either side of where the ship's pointing.
+	t := p.game.tuning.Weapons
+	spread := t.SpreadAngle * math.Pi / 180
+	for i := 0; i < t.SpreadShots; i++ {
+		offset := 0.0
+		if t.SpreadShots > 1 {
+			offset = spread * (float64(i)/float64(t.SpreadShots-1) - 0.5)
+		}
+		p.fireLaser(p.rotation + offset)
+	}
+	p.game.audio.Play(w.sound)
+}
+
+func (w *SpreadShot) Holster() {}
+
+func (w *SpreadShot) Draw(*ebiten.Image, *Player) {}
+
+// BeamCannon charges while fire is held down, and fires a beam when it's let go. The longer it's
+// charged, the longer and wider the beam.
+type BeamCannon struct {
+	coolDown *Timer // Pause after a beam before charging can start again.
+	charge   *Timer // How long it's been charging for.
+	charging bool   // Is it charging?
+	sound    Sound  // What a beam sounds like.
+}
+
+// NewBeamCannon is a factory method which creates a beam cannon.
+func NewBeamCannon(t *Tuning) *BeamCannon {
+	return &BeamCannon{
+		coolDown: NewTimer(time.Duration(t.Weapons.BeamCoolDown)),
+		charge:   NewTimer(time.Duration(t.Weapons.BeamChargeTime)),
+		sound:    SoundLaserThree,
+	}
+}
+
+func (w *BeamCannon) Kind() WeaponKind { return WeaponBeam }
+
+func (w *BeamCannon) Update(p *Player, firing bool) {
+	w.coolDown.Update()
+
+	if firing && w.coolDown.IsReady() {
+		if !w.charging {
+			w.charging = true
+			w.charge.Reset()
+		}
+		w.charge.Update()
+		return
+	}
+
+	if w.charging {
+		// A quick tap still fires a short beam.
+		power := max(1-w.charge.Remaining(), beamMinPower)
+		p.game.addProjectile(NewBeam(p.nose(), p.rotation, power, p.game))
+		p.game.audio.Play(w.sound)
+		w.charging = false
+		w.coolDown.Reset()
+	}
+}
+
+func (w *BeamCannon) Holster() {
+	w.charging = false
+}
+
+// Draw draws a glow on the ship's nose that grows as the beam charges.
+func (w *BeamCannon) Draw(screen *ebiten.Image, p *Player) {
+	if !w.charging {
+		return
+	}
+	nose := p.nose()
+	power := 1 - w.charge.Remaining()
+	vector.DrawFilledCircle(screen, float32(nose.X), float32(nose.Y), float32(3+9*power), color.RGBA{R: 120, G: 230, B: 255, A: 200}, true)
+}
+
+// MissileLauncher fires missiles that home in on aliens and large meteors. It keeps looking for
+// something in front of the ship to lock on to, and shows a reticle on it; a missile fired then
+// goes after that.
+type MissileLauncher struct {
+	coolDown *Timer        // Pause between missiles.
+	lock     resolv.IShape // What the next missile will be locked on to, if anything.
+	sound    Sound         // What a launch sounds like.
+}
+
+// NewMissileLauncher is a factory method which creates a missile launcher.
+func NewMissileLauncher(t *Tuning) *MissileLauncher {
+	return &MissileLauncher{
+		coolDown: NewTimer(time.Duration(t.Weapons.MissileCoolDown)),
+		sound:    SoundLaserOne,
+	}
+}
+
+func (w *MissileLauncher) Kind() WeaponKind { return WeaponMissiles }
+
+func (w *MissileLauncher) Update(p *Player, firing bool) {
+	w.coolDown.Update()
+	w.lock = p.game.lockOn(p.nose(), p.rotation)
+	if !firing || !w.coolDown.IsReady() {
+		return
+	}
+	w.coolDown.Reset()
+
+	p.game.addProjectile(NewMissile(p.nose(), p.rotation, w.lock, p.game))
+	p.game.audio.Play(w.sound)
+}
+
+func (w *MissileLauncher) Holster() {
+	w.lock = nil
+}
+
+// Draw draws the lock-on reticle: a ring around what's locked on to, with a mark at each side.
+func (w *MissileLauncher) Draw(screen *ebiten.Image, p *Player) {
+	if w.lock == nil {
+		return
+	}
+	centre, ok := p.game.targetCentre(w.lock)
+	if !ok {
+		return
+	}
+
+	r := float32(reticleRadius)
+	if c, ok := w.lock.(*resolv.Circle); ok {
+		r = float32(c.Radius()) + 6
+	}
+	x, y := float32(centre.X), float32(centre.Y)
+	colour := color.RGBA{R: 255, G: 140, B: 40, A: 255}
+	vector.StrokeCircle(screen, x, y, r, 1.5, colour, true)
+	vector.StrokeLine(screen, x-r-8, y, x-r+4, y, 1.5, colour, true)
+	vector.StrokeLine(screen, x+r-4, y, x+r+8, y, 1.5, colour, true)
+	vector.StrokeLine(screen, x, y-r-8, x, y-r+4, 1.5, colour, true)
+	vector.StrokeLine(screen, x, y+r-4, x, y+r+8, 1.5, colour, true)
+}
+
+// MineLayer drops mines behind the ship. There can only be so many about at once; dropping
+// another when there are already that many is refused.
+type MineLayer struct {
+	coolDown *Timer // Pause between mines.
+	sound    Sound  // What dropping one sounds like.
+}
+
+// NewMineLayer is a factory method which creates a mine layer.
+func NewMineLayer(t *Tuning) *MineLayer {
+	return &MineLayer{
+		coolDown: NewTimer(time.Duration(t.Weapons.MineCoolDown)),
+		sound:    SoundBeatTwo,
+	}
+}
+
+func (w *MineLayer) Kind() WeaponKind { return WeaponMines }
+
+func (w *MineLayer) Update(p *Player, firing bool) {
+	w.coolDown.Update()
+	if !firing || !w.coolDown.IsReady() {
+		return
+	}
+
+	mines := 0
+	for _, s := range p.game.projectiles {
+		if _, ok := s.(*Mine); ok {
+			mines++
+		}
+	}
+	if mines >= p.game.tuning.Weapons.MaxMines {
+		return
+	}
+	w.coolDown.Reset()
+
+	p.game.addProjectile(NewMine(p.tail(), p.game))
+	p.game.audio.Play(w.sound)
+}
+
+func (w *MineLayer) Holster() {}
+
+func (w *MineLayer) Draw(*ebiten.Image, *Player) {}
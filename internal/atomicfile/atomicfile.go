@@ -0,0 +1,44 @@
+// Package atomicfile writes files so that a crash halfway through never leaves a half-written one.
+// The game and the leaderboard both keep their files with it.
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes data to path with permissions perm, creating its folder if needed. The data
+// goes to a temporary file first, which is then renamed over path, so a crash halfway through
+// leaves the old file intact rather than a half-written one.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+
+	// Clean up the temporary file if anything goes wrong. After the rename there's nothing to remove.
+	defer os.Remove(tmp)
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,227 @@
+package leaderboard
+
+import (
+	"asteroids/internal/atomicfile"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	requestTimeout   = 45 * time.Second // How long we give the server to answer. It plays a submission's replay back before it does.
+	maxRetryInterval = 5 * time.Minute  // The longest Run waits between retries.
+)
+
+// RejectedError is returned when the server refuses a submission. Retrying it won't help, so it's
+// dropped from the queue.
+type RejectedError struct {
+	Status int
+	Reason string
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("submission rejected (%d): %s", e.Status, e.Reason)
+}
+
+// Client talks to a leaderboard server. Submissions are queued in a file first and sent from
+// there, so a game finished while the server's down (or the laptop's off the network) is sent
+// the next time it can be.
+type Client struct {
+	baseURL   string
+	http      *http.Client
+	queuePath string       // Where queued submissions are kept, or "" to keep them in memory only.
+	mu        sync.Mutex   // Guards queue, and makes sure only one Flush runs at a time.
+	queue     []Submission // Submissions not yet accepted by the server, oldest first.
+}
+
+// NewClient creates a client for the server at baseURL (e.g. "http://10.0.0.5:8080"), queueing
+// submissions in the file at queuePath. Anything already queued there is loaded.
+func NewClient(baseURL, queuePath string) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("leaderboard URL %q must be http or https", baseURL)
+	}
+
+	c := &Client{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		http:      &http.Client{Timeout: requestTimeout},
+		queuePath: queuePath,
+	}
+
+	if queuePath == "" {
+		return c, nil
+	}
+	contents, err := os.ReadFile(queuePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(contents, &c.queue); err != nil {
+		return c, fmt.Errorf("reading %s: %w", queuePath, err)
+	}
+	return c, nil
+}
+
+// Submit queues s and tries to send everything in the queue. s gets a new ID if it hasn't got one.
+// An error means s is still queued (or was rejected, see RejectedError).
+func (c *Client) Submit(ctx context.Context, s Submission) error {
+	if s.ID == "" {
+		s.ID = NewID()
+	}
+
+	c.mu.Lock()
+	c.queue = append(c.queue, s)
+	err := c.saveQueue()
+	c.mu.Unlock()
+	if err != nil {
+		log.Println("Error saving leaderboard queue:", err)
+	}
+
+	return c.Flush(ctx)
+}
+
+// Pending returns how many submissions are waiting to be sent.
+func (c *Client) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.queue)
+}
+
+// Flush sends the queued submissions, oldest first. It stops at the first one that can't be sent,
+// leaving it and the rest queued. Submissions the server rejects are dropped.
+func (c *Client) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var rejected error
+	for len(c.queue) > 0 {
+		err := c.send(ctx, c.queue[0])
+		var r *RejectedError
+		if err != nil && !errors.As(err, &r) {
+			return err
+		}
+		if r != nil {
+			rejected = err
+		}
+
+		c.queue = c.queue[1:]
+		if err := c.saveQueue(); err != nil {
+			log.Println("Error saving leaderboard queue:", err)
+		}
+	}
+	return rejected
+}
+
+// Run flushes the queue every interval until ctx is done, backing off (up to maxRetryInterval)
+// while the server can't be reached.
+func (c *Client) Run(ctx context.Context, interval time.Duration) {
+	wait := interval
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		var r *RejectedError
+		if err := c.Flush(ctx); err != nil && !errors.As(err, &r) {
+			wait = min(wait*2, maxRetryInterval)
+			continue
+		}
+		wait = interval
+	}
+}
+
+// Top fetches the best entries matching q.
+func (c *Client) Top(ctx context.Context, q Query) ([]Entry, error) {
+	params := url.Values{}
+	params.Set("limit", strconv.Itoa(q.limit()))
+	if q.Mode != "" {
+		params.Set("mode", q.Mode)
+	}
+	if q.Seed != 0 {
+		params.Set("seed", strconv.FormatUint(q.Seed, 10))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/scores?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching scores: %s", resp.Status)
+	}
+
+	var body ScoresResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return body.Entries, nil
+}
+
+// send posts one submission to the server.
+func (c *Client) send(ctx context.Context, s Submission) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/scores", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated:
+		return nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		var body ErrorResponse
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&body)
+		return &RejectedError{Status: resp.StatusCode, Reason: body.Error}
+	default:
+		return fmt.Errorf("submitting score: %s", resp.Status)
+	}
+}
+
+// saveQueue writes the queue to its file. c.mu must be held.
+func (c *Client) saveQueue() error {
+	if c.queuePath == "" {
+		return nil
+	}
+
+	contents, err := json.Marshal(c.queue)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(c.queuePath, contents, 0640)
+}
@@ -0,0 +1,249 @@
+package leaderboard
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testServer is a leaderboard server backed by a store in a temporary folder. While down is set
+// it answers every request with a 503, as if it were being restarted.
+type testServer struct {
+	*httptest.Server
+	store *Store
+	down  atomic.Bool
+}
+
+// newTestServer starts a test server that checks submissions with verify, which can be nil.
+func newTestServer(t *testing.T, verify Verifier) *testServer {
+	t.Helper()
+
+	store, err := OpenStore(filepath.Join(t.TempDir(), "leaderboard.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &testServer{store: store}
+	handler := NewHandler(store, verify)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.down.Load() {
+			http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// newTestClient creates a client for s that queues in a temporary folder, and returns it with
+// its queue file.
+func newTestClient(t *testing.T, s *testServer) (*Client, string) {
+	t.Helper()
+
+	queue := filepath.Join(t.TempDir(), "leaderboard-queue.json")
+	c, err := NewClient(s.URL, queue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, queue
+}
+
+func TestSubmitAndTop(t *testing.T) {
+	s := newTestServer(t, nil)
+	c, _ := newTestClient(t, s)
+	ctx := context.Background()
+
+	for _, sub := range []Submission{
+		submission("AAA", 300, "classic", 1),
+		submission("BBB", 500, "daily", 20250101),
+		submission("CCC", 100, "seeded", 42),
+		submission("DDD", 400, "daily", 20250102),
+	} {
+		if err := c.Submit(ctx, sub); err != nil {
+			t.Fatalf("submitting %s: %v", sub.Initials, err)
+		}
+	}
+	if c.Pending() != 0 {
+		t.Errorf("%d submissions still queued", c.Pending())
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"everything", Query{}, []string{"BBB", "DDD", "AAA", "CCC"}},
+		{"top 2", Query{Limit: 2}, []string{"BBB", "DDD"}},
+		{"one mode", Query{Mode: "daily"}, []string{"BBB", "DDD"}},
+		{"one seed", Query{Seed: 42}, []string{"CCC"}},
+		{"mode and seed", Query{Mode: "daily", Seed: 20250102}, []string{"DDD"}},
+		{"nothing matching", Query{Mode: "classic", Seed: 42}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := c.Top(ctx, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.Initials)
+				if e.Replay != nil {
+					t.Errorf("%s's entry came with its replay", e.Initials)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSubmitIsStoredOnce(t *testing.T) {
+	s := newTestServer(t, nil)
+	c, _ := newTestClient(t, s)
+	ctx := context.Background()
+
+	sub := submission("AAA", 300, "classic", 1)
+	for range 2 {
+		if err := c.Submit(ctx, sub); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if entries := s.store.Top(Query{}); len(entries) != 1 {
+		t.Errorf("a retried submission was stored %d times", len(entries))
+	}
+	if replay, ok := s.store.Replay(sub.ID); !ok || string(replay) != "replay" {
+		t.Errorf("replay = %q, %v, want the submitted one", replay, ok)
+	}
+}
+
+func TestSubmitQueuesWhileServerIsDown(t *testing.T) {
+	s := newTestServer(t, nil)
+	c, queue := newTestClient(t, s)
+	ctx := context.Background()
+
+	s.down.Store(true)
+	if err := c.Submit(ctx, submission("AAA", 300, "classic", 1)); err == nil {
+		t.Fatal("submitting to a server that's down worked")
+	}
+	if err := c.Submit(ctx, submission("BBB", 200, "classic", 2)); err == nil {
+		t.Fatal("submitting to a server that's down worked")
+	}
+	if c.Pending() != 2 {
+		t.Fatalf("%d submissions queued, want 2", c.Pending())
+	}
+
+	// The queue outlives the game.
+	reopened, err := NewClient(s.URL, queue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Pending() != 2 {
+		t.Fatalf("%d submissions queued after reopening, want 2", reopened.Pending())
+	}
+
+	s.down.Store(false)
+	if err := reopened.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Pending() != 0 {
+		t.Errorf("%d submissions still queued once the server was back", reopened.Pending())
+	}
+	if entries := s.store.Top(Query{}); len(entries) != 2 {
+		t.Errorf("server has %d entries, want 2", len(entries))
+	}
+}
+
+func TestRunRetriesUntilServerIsBack(t *testing.T) {
+	s := newTestServer(t, nil)
+	c, _ := newTestClient(t, s)
+
+	s.down.Store(true)
+	if err := c.Submit(context.Background(), submission("AAA", 300, "classic", 1)); err == nil {
+		t.Fatal("submitting to a server that's down worked")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx, 10*time.Millisecond)
+
+	time.Sleep(50 * time.Millisecond)
+	if c.Pending() != 1 {
+		t.Fatalf("%d submissions queued while the server was down, want 1", c.Pending())
+	}
+
+	s.down.Store(false)
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Pending() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the queued submission wasn't retried once the server was back")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if entries := s.store.Top(Query{}); len(entries) != 1 {
+		t.Errorf("server has %d entries, want 1", len(entries))
+	}
+}
+
+func TestRejectedSubmissionIsDropped(t *testing.T) {
+	s := newTestServer(t, func(_ context.Context, s Submission) error {
+		if s.Score > 1000 {
+			return errors.New("nobody's that good")
+		}
+		return nil
+	})
+	c, _ := newTestClient(t, s)
+
+	err := c.Submit(context.Background(), submission("AAA", 5000, "classic", 1))
+	var rejected *RejectedError
+	if !errors.As(err, &rejected) {
+		t.Fatalf("err = %v, want a RejectedError", err)
+	}
+	if rejected.Status != http.StatusUnprocessableEntity || rejected.Reason != "nobody's that good" {
+		t.Errorf("rejected with %d %q", rejected.Status, rejected.Reason)
+	}
+	if c.Pending() != 0 {
+		t.Errorf("a rejected submission is still queued")
+	}
+	if entries := s.store.Top(Query{}); len(entries) != 0 {
+		t.Errorf("server stored %d rejected entries", len(entries))
+	}
+}
+
+func TestNewClientRejectsBadURLs(t *testing.T) {
+	for _, url := range []string{"ftp://example.com", "example.com:8080", "://"} {
+		if _, err := NewClient(url, ""); err == nil {
+			t.Errorf("%q was accepted", url)
+		}
+	}
+}
+
+func TestBusySubmissionStaysQueued(t *testing.T) {
+	s := newTestServer(t, func(context.Context, Submission) error {
+		return ErrBusy
+	})
+	c, _ := newTestClient(t, s)
+
+	err := c.Submit(context.Background(), submission("AAA", 300, "classic", 1))
+	var rejected *RejectedError
+	if err == nil || errors.As(err, &rejected) {
+		t.Fatalf("err = %v, want one that's worth retrying", err)
+	}
+	if c.Pending() != 1 {
+		t.Errorf("%d submissions queued, want 1", c.Pending())
+	}
+}
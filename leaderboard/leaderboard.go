@@ -0,0 +1,103 @@
+// Package leaderboard is the shared high score table: the types the game and the leaderboard
+// server agree on, a file-backed store and HTTP API for the server, and a client for the game that
+// queues submissions while the server can't be reached. This package doesn't depend on ebiten, but
+// the server command does: it checks replays with the game's own logic.
+package leaderboard
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultLimit = 10  // How many entries a query returns if it doesn't say.
+	MaxLimit     = 100 // The most entries a query can ask for.
+)
+
+// Submission is one finished game, as sent by the game. It carries the game's replay, so the
+// server can play it back and check the score is real.
+type Submission struct {
+	ID       string    `json:"id"` // Picked by the client, so a submission that's retried is only stored once.
+	Initials string    `json:"initials"`
+	Score    int       `json:"score"`
+	Level    int       `json:"level"` // The level the player reached.
+	Mode     string    `json:"mode"`  // e.g. "classic", "seeded" or "daily".
+	Seed     uint64    `json:"seed"`
+	Date     time.Time `json:"date"`             // When the game was played.
+	Replay   []byte    `json:"replay,omitempty"` // The game's replay file. Left out when entries are listed.
+}
+
+// Verifier checks a submission is genuine, returning why not if it isn't. The server uses one to
+// play replays back; this package doesn't know how. It should give up once ctx is done.
+type Verifier func(ctx context.Context, s Submission) error
+
+// ErrBusy is returned by a Verifier that can't check a submission right now. The submission isn't
+// refused; the client's told to try again later.
+var ErrBusy = errors.New("the server's too busy to check the replay, try again later")
+
+// Entry is a submission the server has accepted.
+type Entry struct {
+	Submission
+	Received time.Time `json:"received"` // When the server got it.
+}
+
+// Query picks which entries to fetch.
+type Query struct {
+	Limit int    // How many to return, best first. 0 means DefaultLimit.
+	Mode  string // Only entries played in this mode, if set.
+	Seed  uint64 // Only entries played with this seed, if set.
+}
+
+// NewID returns a new random submission ID.
+func NewID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Validate checks that a submission makes sense.
+func (s Submission) Validate() error {
+	switch {
+	case s.ID == "" || len(s.ID) > 64:
+		return errors.New("bad id")
+	case len(s.Initials) < 1 || len(s.Initials) > 3:
+		return errors.New("initials must be 1 to 3 letters")
+	case s.Score < 0:
+		return errors.New("score can't be negative")
+	case s.Level < 1:
+		return errors.New("level must be at least 1")
+	case s.Mode == "" || len(s.Mode) > 16:
+		return errors.New("bad mode")
+	case len(s.Replay) == 0:
+		return errors.New("missing replay")
+	}
+
+	for _, r := range s.ID {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+			return errors.New("bad id")
+		}
+	}
+	for _, r := range s.Initials {
+		if r < 'A' || r > 'Z' {
+			return fmt.Errorf("initials must be A to Z, not %q", s.Initials)
+		}
+	}
+	return nil
+}
+
+// limit returns how many entries q asks for, within bounds.
+func (q Query) limit() int {
+	if q.Limit <= 0 {
+		return DefaultLimit
+	}
+	return min(q.Limit, MaxLimit)
+}
+
+// matches reports whether e is one of the entries q asks for.
+func (q Query) matches(e Entry) bool {
+	return (q.Mode == "" || e.Mode == q.Mode) && (q.Seed == 0 || e.Seed == q.Seed)
+}
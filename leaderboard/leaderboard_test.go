@@ -0,0 +1,69 @@
+package leaderboard
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// submission returns a valid submission scoring score, in mode with seed.
+func submission(initials string, score int, mode string, seed uint64) Submission {
+	return Submission{
+		ID:       NewID(),
+		Initials: initials,
+		Score:    score,
+		Level:    1 + score/100,
+		Mode:     mode,
+		Seed:     seed,
+		Date:     time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		Replay:   []byte("replay"),
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := submission("ABC", 100, "classic", 1).Validate(); err != nil {
+		t.Fatalf("valid submission rejected: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(*Submission)
+	}{
+		{"no id", func(s *Submission) { s.ID = "" }},
+		{"long id", func(s *Submission) { s.ID = strings.Repeat("a", 65) }},
+		{"id with a slash", func(s *Submission) { s.ID = "../scores" }},
+		{"no initials", func(s *Submission) { s.Initials = "" }},
+		{"long initials", func(s *Submission) { s.Initials = "ABCD" }},
+		{"lower case initials", func(s *Submission) { s.Initials = "abc" }},
+		{"negative score", func(s *Submission) { s.Score = -1 }},
+		{"level 0", func(s *Submission) { s.Level = 0 }},
+		{"no mode", func(s *Submission) { s.Mode = "" }},
+		{"long mode", func(s *Submission) { s.Mode = strings.Repeat("m", 17) }},
+		{"no replay", func(s *Submission) { s.Replay = nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := submission("ABC", 100, "classic", 1)
+			tt.change(&s)
+			if err := s.Validate(); err == nil {
+				t.Errorf("%+v was accepted", s)
+			}
+		})
+	}
+}
+
+func TestQueryLimit(t *testing.T) {
+	tests := []struct {
+		limit, want int
+	}{
+		{0, DefaultLimit},
+		{-5, DefaultLimit},
+		{3, 3},
+		{MaxLimit + 1, MaxLimit},
+	}
+	for _, tt := range tests {
+		if got := (Query{Limit: tt.limit}).limit(); got != tt.want {
+			t.Errorf("limit %d = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
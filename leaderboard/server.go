@@ -0,0 +1,123 @@
+package leaderboard
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+const maxSubmissionSize = 1 << 20 // The biggest request body we'll read.
+
+// ScoresResponse is the body returned by GET /api/scores.
+type ScoresResponse struct {
+	Entries []Entry `json:"entries"`
+}
+
+// ErrorResponse is the body returned when a request fails.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler returns the leaderboard's HTTP API, backed by store:
+//
+//	POST /api/scores                           submit a Submission; 201 with the Entry, or 200 if it was already stored
+//	GET  /api/scores?limit=N&mode=M&seed=S     the top N entries, optionally for one mode and/or seed
+//	GET  /api/replays/{id}                     the replay file of entry id
+//
+// Submissions that verify rejects are refused with a 422, or put off with a 503 if it returns
+// ErrBusy. verify can be nil, which trusts every submission.
+func NewHandler(store *Store, verify Verifier) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/scores", func(w http.ResponseWriter, r *http.Request) {
+		var sub Submission
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSubmissionSize)).Decode(&sub); err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := sub.Validate(); err != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if verify != nil {
+			err := verify(r.Context(), sub)
+			if errors.Is(err, ErrBusy) {
+				writeJSON(w, http.StatusServiceUnavailable, ErrorResponse{Error: err.Error()})
+				return
+			}
+			if err != nil {
+				log.Printf("Rejected %d points from %s: %v", sub.Score, r.RemoteAddr, err)
+				writeJSON(w, http.StatusUnprocessableEntity, ErrorResponse{Error: err.Error()})
+				return
+			}
+		}
+
+		e, isNew, err := store.Add(sub)
+		if err != nil {
+			log.Println("Error storing submission:", err)
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "couldn't store the submission"})
+			return
+		}
+
+		status := http.StatusOK
+		if isNew {
+			status = http.StatusCreated
+		}
+		e.Replay = nil
+		writeJSON(w, status, e)
+	})
+
+	mux.HandleFunc("GET /api/replays/{id}", func(w http.ResponseWriter, r *http.Request) {
+		replay, ok := store.Replay(r.PathValue("id"))
+		if !ok {
+			writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "no such entry"})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+r.PathValue("id")+`.replay"`)
+		if _, err := w.Write(replay); err != nil {
+			log.Println("Error writing response:", err)
+		}
+	})
+
+	mux.HandleFunc("GET /api/scores", func(w http.ResponseWriter, r *http.Request) {
+		var q Query
+		var err error
+
+		params := r.URL.Query()
+		if v := params.Get("limit"); v != "" {
+			if q.Limit, err = strconv.Atoi(v); err != nil {
+				writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "bad limit"})
+				return
+			}
+		}
+		if v := params.Get("seed"); v != "" {
+			if q.Seed, err = strconv.ParseUint(v, 10, 64); err != nil {
+				writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "bad seed"})
+				return
+			}
+		}
+		q.Mode = params.Get("mode")
+
+		entries := store.Top(q)
+		if entries == nil {
+			entries = []Entry{}
+		}
+		writeJSON(w, http.StatusOK, ScoresResponse{Entries: entries})
+	})
+
+	return mux
+}
+
+// writeJSON writes v as the response body.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println("Error writing response:", err)
+	}
+}
@@ -0,0 +1,83 @@
+package leaderboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+// serve sends a request to a handler backed by store, and returns the response.
+func serve(store *Store, method, target string, body []byte) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	NewHandler(store, nil).ServeHTTP(w, httptest.NewRequest(method, target, bytes.NewReader(body)))
+	return w
+}
+
+func TestHandlerRejectsBadRequests(t *testing.T) {
+	store, err := OpenStore(filepath.Join(t.TempDir(), "leaderboard.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invalid, err := json.Marshal(submission("abc", 100, "classic", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		method, target string
+		body           []byte
+		want           int
+	}{
+		{"not json", http.MethodPost, "/api/scores", []byte("{"), http.StatusBadRequest},
+		{"invalid submission", http.MethodPost, "/api/scores", invalid, http.StatusUnprocessableEntity},
+		{"bad limit", http.MethodGet, "/api/scores?limit=ten", nil, http.StatusBadRequest},
+		{"bad seed", http.MethodGet, "/api/scores?seed=-1", nil, http.StatusBadRequest},
+		{"unknown replay", http.MethodGet, "/api/replays/nope", nil, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(store, tt.method, tt.target, tt.body)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+
+			var body ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil || body.Error == "" {
+				t.Errorf("body doesn't say what went wrong: %v", err)
+			}
+		})
+	}
+
+	if entries := store.Top(Query{}); len(entries) != 0 {
+		t.Errorf("store has %d entries after bad requests", len(entries))
+	}
+}
+
+func TestStoreSurvivesReopening(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leaderboard.json")
+	store, err := OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sub := submission("AAA", 300, "classic", 1)
+	if _, isNew, err := store.Add(sub); err != nil || !isNew {
+		t.Fatalf("adding: new %v, %v", isNew, err)
+	}
+
+	reopened, err := OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, isNew, err := reopened.Add(sub); err != nil || isNew {
+		t.Errorf("adding it again after reopening: new %v, %v", isNew, err)
+	}
+	if entries := reopened.Top(Query{}); len(entries) != 1 || entries[0].ID != sub.ID {
+		t.Errorf("reopened store has %+v", entries)
+	}
+}
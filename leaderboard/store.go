@@ -0,0 +1,145 @@
+package leaderboard
+
+import (
+	"asteroids/internal/atomicfile"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+const storeVersion = 1 // Bump this when the layout of the store file changes.
+
+// storeFile is the layout of the store file.
+type storeFile struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+// Store keeps every accepted entry in a JSON file. The whole table lives in memory and the file is
+// rewritten (atomically) on every change, which is plenty for an office tournament.
+type Store struct {
+	path    string
+	mu      sync.Mutex
+	entries []Entry         // Best first.
+	ids     map[string]bool // The IDs of every entry, so retried submissions aren't stored twice.
+}
+
+// OpenStore opens the store at path, creating it on the first submission if it doesn't exist.
+func OpenStore(path string) (*Store, error) {
+	s := &Store{
+		path: path,
+		ids:  make(map[string]bool),
+	}
+
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file storeFile
+	if err := json.Unmarshal(contents, &file); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if file.Version != storeVersion {
+		return nil, fmt.Errorf("reading %s: unsupported version %d", path, file.Version)
+	}
+
+	s.entries = file.Entries
+	sortEntries(s.entries)
+	for _, e := range s.entries {
+		s.ids[e.ID] = true
+	}
+	return s, nil
+}
+
+// Add stores sub. It returns the new entry, or the one already stored if a submission with the
+// same ID has been seen before, and whether it was new.
+func (s *Store) Add(sub Submission) (Entry, bool, error) {
+	if err := sub.Validate(); err != nil {
+		return Entry{}, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ids[sub.ID] {
+		for _, e := range s.entries {
+			if e.ID == sub.ID {
+				return e, false, nil
+			}
+		}
+	}
+
+	e := Entry{Submission: sub, Received: time.Now().UTC()}
+	entries := append(slices.Clone(s.entries), e)
+	sortEntries(entries)
+
+	if err := s.save(entries); err != nil {
+		return Entry{}, false, err
+	}
+	s.entries = entries
+	s.ids[sub.ID] = true
+	return e, true, nil
+}
+
+// Top returns the best entries matching q, best first. Their replays are left out; see Replay.
+func (s *Store) Top(q Query) []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var top []Entry
+	for _, e := range s.entries {
+		if len(top) == q.limit() {
+			break
+		}
+		if q.matches(e) {
+			e.Replay = nil
+			top = append(top, e)
+		}
+	}
+	return top
+}
+
+// Replay returns the replay of the entry with ID id, if there is one.
+func (s *Store) Replay(id string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.entries {
+		if e.ID == id {
+			return e.Replay, len(e.Replay) > 0
+		}
+	}
+	return nil, false
+}
+
+// save writes entries to the store file.
+func (s *Store) save(entries []Entry) error {
+	contents, err := json.MarshalIndent(storeFile{
+		Version: storeVersion,
+		Entries: entries,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(s.path, contents, 0640)
+}
+
+// sortEntries puts entries in order: best score first, and the earlier of two equal scores first.
+func sortEntries(entries []Entry) {
+	slices.SortStableFunc(entries, func(a, b Entry) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return a.Received.Compare(b.Received)
+	})
+}
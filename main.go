@@ -2,23 +2,53 @@ package main
 
 import (
 	"asteroids/goasteroids"
+	"flag"
+	"log"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 func main() {
-
+	seed := flag.Uint64("seed", 0, "play every game with this seed (0 picks a new one each game)")
+	daily := flag.Bool("daily", false, "play today's daily challenge")
+	replay := flag.String("replay", "", "watch a replay file instead of playing")
+	edit := flag.String("edit", "", "open the level editor on the level set with this name (a new one if there isn't one)")
+	tuning := flag.String("tuning", "", "read the game's tuning from this file instead of tuning.json in the config directory")
+	board := flag.String("leaderboard", "", "send scores to, and show the table from, the leaderboard server at this URL")
+	flag.Parse()
+
+	if *daily {
+		*seed = goasteroids.DailySeed(time.Now())
+	}
 
 	ebiten.SetWindowTitle("Asteroids")
-	ebiten.SetWindowSize(goasteroids.ScreenWidth, goasteroids.ScreenHeight)
+	ebiten.SetTPS(goasteroids.TicksPerSecond)
+
+	// Window size, fullscreen, cursor and volume all come from the player's settings.
+	settings, err := goasteroids.LoadSettings()
+	if err != nil {
+		log.Println("Error loading settings:", err)
+	}
+	goasteroids.UseSettings(settings)
 
-	// Hide the cursor.
-	ebiten.SetCursorMode(ebiten.CursorModeHidden)
+	highScores, err := goasteroids.LoadHighScores()
+	if err != nil {
+		log.Println("Error loading high scores:", err)
+	}
+	goasteroids.UseHighScores(highScores)
 
-	// Set to full screen.
-	ebiten.SetFullscreen(true)
+	if err := goasteroids.UseTuning(*tuning); err != nil {
+		log.Println("Error loading tuning, using the defaults:", err)
+	}
+
+	if *board != "" {
+		if err := goasteroids.UseLeaderboard(*board); err != nil {
+			log.Println("Error connecting to leaderboard:", err)
+		}
+	}
 
-	err := ebiten.RunGame(&goasteroids.Game{})
+	err = ebiten.RunGame(&goasteroids.Game{Seed: *seed, ReplayPath: *replay, EditLevels: *edit})
 	if err != nil {
 		panic(err)
 	}